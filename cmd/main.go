@@ -1,11 +1,17 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"net/http"
 	"os"
 	api "performance-dashboard-backend/internal/api"
+	"performance-dashboard-backend/internal/asana"
+	"performance-dashboard-backend/internal/calendar"
+	"performance-dashboard-backend/internal/config"
 	db "performance-dashboard-backend/internal/database"
+	"performance-dashboard-backend/internal/database/migrations"
+	"performance-dashboard-backend/internal/notify"
 
 	"github.com/joho/godotenv"
 )
@@ -17,18 +23,57 @@ func LoadEnv() {
 	}
 }
 
-func ConnectDatabase() {
-	err := db.ConnectMongoDB()
+// LoadConfig reads the file of -config, or of CONFIG_FILE, overridden by the environment
+func LoadConfig() *config.Config {
+	path := flag.String("config", os.Getenv("CONFIG_FILE"), "path of an optional YAML config file")
+	flag.Parse()
+	cfg, err := config.Load(*path)
+	if err != nil {
+		log.Fatal("Configuration error: ", err)
+	}
+	return cfg
+}
+
+func ConnectDatabase(cfg *config.Config) {
+	err := db.ConnectMongoDB(cfg.Mongo.URI, cfg.Mongo.Database)
 	if err != nil {
 		log.Fatal("Database connection error:", err)
 	}
 }
 
+func ConfigureCollections(cfg *config.Config) {
+	err := db.ConfigureCollections(cfg.Mongo.Collections)
+	if err != nil {
+		log.Fatal("Collection configuration error:", err)
+	}
+}
+
+func ConfigureCalendar(cfg *config.Config) {
+	err := calendar.Configure(cfg.Calendar.TimeZone, cfg.Calendar.WeekStart)
+	if err != nil {
+		log.Fatal("Calendar configuration error:", err)
+	}
+}
+
+func RunMigrations(cfg *config.Config) {
+	err := migrations.Run(db.GetMongoClient(), cfg.Mongo.Database, cfg.Mongo.Collections)
+	if err != nil {
+		log.Fatal("Database migration error:", err)
+	}
+}
+
 func main() {
 	LoadEnv()
-	ConnectDatabase()
+	cfg := LoadConfig()
+	ConfigureCalendar(cfg)
+	ConnectDatabase(cfg)
+	// Before migrations, building indexes would create misnamed collections
+	ConfigureCollections(cfg)
+	RunMigrations(cfg)
+	asana.Configure(cfg.Asana)
+	notify.Configure(cfg.Notify.SlackWebhookURL)
 
 	// asana.SyncronizeWeeklyTasks()
-	api.Init()
-	log.Fatal(http.ListenAndServe(":"+os.Getenv("SERVER_PORT"), nil))
+	api.Init(cfg)
+	log.Fatal(http.ListenAndServe(cfg.Addr(), nil))
 }
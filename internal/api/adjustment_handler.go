@@ -0,0 +1,214 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"performance-dashboard-backend/internal/calendar"
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"slices"
+	"time"
+)
+
+/// ======================================================
+/// ============= Score Adjustment Handler ===============
+
+// adjustmentsRequireApproval reports whether adjustments of managers wait for an admin, those of admins never do
+func adjustmentsRequireApproval() bool {
+	return settings.ScoreAdjustment.RequiresApproval
+}
+
+// HandleGetScoreAdjustments lists adjustments. Query: the usual list parameters, status=pending|approved|rejected
+func HandleGetScoreAdjustments(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	spec, err := parseQuerySpec(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	adjustments, nextCursor, err := collectionmodels.GetScoreAdjustmentsPage(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ScoreAdjustment, spec, r.URL.Query().Get("status"))
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, adjustments, nextCursor)
+}
+
+// HandleAddNewScoreAdjustment grants or deducts points of a member for one week.
+// Admins and managers of the team only. Body: Email, Team, WeekStart, Point, Category, Reason
+func HandleAddNewScoreAdjustment(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	email, _ := body["Email"].(string)
+	team, _ := body["Team"].(string)
+	point, ok := body["Point"].(float64)
+	weekStart := parseOptionalTime(body, "WeekStart")
+	category, _ := body["Category"].(string)
+	reason, _ := body["Reason"].(string)
+	if email == "" || team == "" || !ok || point == 0 || weekStart == nil || reason == "" {
+		http.Error(w, "Email, Team, WeekStart, Reason and a non zero Point are required", http.StatusBadRequest)
+		return
+	}
+	allowed, ok := canManageTeam(r, team)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !allowed {
+		http.Error(w, "Forbidden: no access to team "+team, http.StatusForbidden)
+		return
+	}
+
+	client := db.GetMongoClient()
+	dbName := db.GetDatabaseName()
+	attribution, err := collectionmodels.LoadTeamAttribution(client, dbName, db.GetCollections().StaffMember)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	if !slices.Contains(attribution.MembersOf(team, calendar.StartOfWeek(*weekStart), calendar.EndOfWeek(*weekStart)), email) {
+		http.Error(w, email+" does not belong to team "+team+" that week", http.StatusBadRequest)
+		return
+	}
+
+	createdBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	isAdmin, _ := isAdminRequest(r)
+	adjustment := &collectionmodels.ScoreAdjustment{
+		Email:     email,
+		Team:      team,
+		WeekStart: *weekStart,
+		Point:     point,
+		Category:  category,
+		Reason:    reason,
+		Status:    collectionmodels.AdjustmentApproved,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	if adjustmentsRequireApproval() && !isAdmin {
+		adjustment.Status = collectionmodels.AdjustmentPending
+	}
+	err = collectionmodels.InsertScoreAdjustment(client, dbName, db.GetCollections().ScoreAdjustment, adjustment)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(adjustment)
+}
+
+// HandleUpdateScoreAdjustment changes the Point, Category and Reason of a pending adjustment. Body: ID, Point, Category, Reason
+func HandleUpdateScoreAdjustment(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	adjustment, ok := storedAdjustment(w, r, body)
+	if !ok {
+		return
+	}
+	point, ok := body["Point"].(float64)
+	category, _ := body["Category"].(string)
+	reason, _ := body["Reason"].(string)
+	if !ok || point == 0 || reason == "" {
+		http.Error(w, "Reason and a non zero Point are required", http.StatusBadRequest)
+		return
+	}
+	adjustment.Point, adjustment.Category, adjustment.Reason = point, category, reason
+	err := collectionmodels.UpdateScoreAdjustment(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ScoreAdjustment, adjustment)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Score adjustment updated successfully"}`))
+}
+
+// HandleDeleteScoreAdjustment deletes an adjustment, only admins may delete one that was reviewed. Body: ID
+func HandleDeleteScoreAdjustment(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	adjustment, ok := storedAdjustment(w, r, body)
+	if !ok {
+		return
+	}
+	if isAdmin, _ := isAdminRequest(r); !isAdmin && adjustment.Status != collectionmodels.AdjustmentPending {
+		http.Error(w, "Forbidden: "+collectionmodels.ErrAdjustmentReviewed.Error(), http.StatusForbidden)
+		return
+	}
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	err := collectionmodels.DeleteScoreAdjustment(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ScoreAdjustment, adjustment.ID, deletedBy)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Score adjustment deleted successfully"}`))
+}
+
+// HandleReviewScoreAdjustment approves or rejects a pending adjustment. Admin only. Body: ID, Approve
+func HandleReviewScoreAdjustment(w http.ResponseWriter, r *http.Request) {
+	isAdmin, ok := isAdminRequest(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !isAdmin {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	id, err := parseObjectID(body, "ID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	approve, ok := body["Approve"].(bool)
+	if !ok {
+		http.Error(w, "Approve is required", http.StatusBadRequest)
+		return
+	}
+	reviewedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	err = collectionmodels.ReviewScoreAdjustment(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ScoreAdjustment, id, approve, reviewedBy)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Score adjustment reviewed successfully"}`))
+}
+
+// storedAdjustment loads the adjustment with the body's ID and checks the user manages its team.
+// It writes the error response and returns false otherwise.
+func storedAdjustment(w http.ResponseWriter, r *http.Request, body map[string]interface{}) (*collectionmodels.ScoreAdjustment, bool) {
+	id, err := parseObjectID(body, "ID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	adjustment, err := collectionmodels.FindByID[collectionmodels.ScoreAdjustment](db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ScoreAdjustment, id)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return nil, false
+	}
+	allowed, ok := canManageTeam(r, adjustment.Team)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	if !allowed {
+		http.Error(w, "Forbidden: no access to team "+adjustment.Team, http.StatusForbidden)
+		return nil, false
+	}
+	return adjustment, true
+}
@@ -4,23 +4,35 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"os"
+	"net/url"
+	"performance-dashboard-backend/internal/calendar"
+	"performance-dashboard-backend/internal/config"
 	db "performance-dashboard-backend/internal/database"
 	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"slices"
+	"strconv"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// settings is the configuration passed to Init
+var settings config.Config
+
 // CORS middleware
 func CORSMiddleware(next http.Handler) http.Handler {
 
-	var frontEndURL = os.Getenv("FRONTEND_URL")
+	var frontEndURL = settings.Server.FrontendURL
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", frontEndURL)
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Next-Cursor, Content-Disposition")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -41,6 +53,9 @@ type SessionData struct {
 
 var sessions = map[string]SessionData{}
 
+// PostHandlerPerformancePoint returns the points of each identifier per bucket, empty buckets have zero points.
+// Query: kind=member|team|project (isTeam=true is kept for older clients), granularity=range|day|week|month|quarter|sprint,
+// sprintStart and sprintDays for sprints. Projects are given by name, alias or ID.
 func PostHandlerPerformancePoint(w http.ResponseWriter, r *http.Request) {
 
 	var body map[string]interface{}
@@ -50,8 +65,22 @@ func PostHandlerPerformancePoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isTeamStr := r.URL.Query().Get("isTeam")
-	isWeeklyStr := r.URL.Query().Get("isWeekly")
+	query := r.URL.Query()
+	kind, err := parseIdentifierKind(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	unit := query.Get("granularity")
+	// isWeekly=true is kept for older clients
+	if unit == "" && query.Get("isWeekly") == "true" {
+		unit = string(calendar.UnitWeek)
+	}
+	granularity, err := calendar.ParseGranularity(unit, query.Get("sprintStart"), query.Get("sprintDays"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	startTimeStr := body["startDate"].(string)
 	endTimeStr := body["endDate"].(string)
 	identifiersInterface := body["identifiers"].([]interface{})
@@ -62,15 +91,11 @@ func PostHandlerPerformancePoint(w http.ResponseWriter, r *http.Request) {
 	startTime, _ := time.Parse(time.RFC3339, startTimeStr)
 	endTime, _ := time.Parse(time.RFC3339, endTimeStr)
 
-	var results []db.PerformancePointTotalWithTime
-	for _, id := range identifiers {
-		res, err := db.GetPerformancePoints(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), id, startTime, endTime, isTeamStr == "true", isWeeklyStr == "true")
-		if err != nil {
-			log.Fatal(err)
-			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		results = append(results, res...)
+	results, err := performancePoints(identifiers, startTime, endTime, kind, granularity)
+	if err != nil {
+		log.Println("Database error:", err)
+		writeDatabaseError(w, err)
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 
@@ -115,12 +140,14 @@ func PostHandlerStaffMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	teamsStrs := body["teams"].([]interface{})
+	// Former members are needed to look up historical performance
+	includeDeleted, _ := body["includeDeleted"].(bool)
 
 	var results []*collectionmodels.Member
 
 	if len(teamsStrs) == 0 && isAdmin {
 		// If no teams are specified, return all members
-		res, err := db.GetMembersByTeam(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), "")
+		res, err := db.GetMembersByTeam(db.GetDatabaseName(), db.GetCollections().StaffMember, "", includeDeleted)
 		if err != nil {
 			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -138,13 +165,20 @@ func PostHandlerStaffMember(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		// A member of several teams is only listed once
+		seen := map[string]bool{}
 		for _, team := range teams {
-			res, err := db.GetMembersByTeam(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), team)
+			res, err := db.GetMembersByTeam(db.GetDatabaseName(), db.GetCollections().StaffMember, team, includeDeleted)
 			if err != nil {
 				http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 				return
 			}
-			results = append(results, res...)
+			for _, member := range res {
+				if !seen[member.Email] {
+					seen[member.Email] = true
+					results = append(results, member)
+				}
+			}
 		}
 
 		if len(results) > 0 {
@@ -153,7 +187,7 @@ func PostHandlerStaffMember(w http.ResponseWriter, r *http.Request) {
 				// if you are not manager of that team, return only your own info
 				var filteredResults []*collectionmodels.Member
 				for _, member := range results {
-					if contains(managerOfTeams, member.Team) || member.Email == email {
+					if containsAny(managerOfTeams, member.TeamsAt(time.Now())) || member.Email == email {
 						filteredResults = append(filteredResults, member)
 					}
 				}
@@ -175,6 +209,208 @@ func contains(slice []string, s string) bool {
 	return false
 }
 
+// parseQuerySpec reads the paging, sorting and filtering parameters shared by the list endpoints:
+// limit, cursor, sort, order (asc|desc), team, project, member, startWeekFrom, startWeekTo (RFC3339), includeDeleted
+func parseQuerySpec(r *http.Request) (collectionmodels.QuerySpec, error) {
+	q := r.URL.Query()
+	spec := collectionmodels.QuerySpec{
+		Cursor:    q.Get("cursor"),
+		SortField: q.Get("sort"),
+		SortDesc:  q.Get("order") == "desc",
+		Team:      q.Get("team"),
+		Project:   q.Get("project"),
+		Member:    q.Get("member"),
+
+		IncludeDeleted: q.Get("includeDeleted") == "true",
+	}
+	if limitStr := q.Get("limit"); limitStr != "" {
+		limit, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit < 0 {
+			return spec, errors.New("invalid limit")
+		}
+		spec.Limit = limit
+	}
+	if fromStr := q.Get("startWeekFrom"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return spec, errors.New("invalid startWeekFrom")
+		}
+		spec.StartWeekFrom = &from
+	}
+	if toStr := q.Get("startWeekTo"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return spec, errors.New("invalid startWeekTo")
+		}
+		spec.StartWeekTo = &to
+	}
+	return spec, nil
+}
+
+// parseOptionalTime reads an RFC3339 date from the body, nil when missing or invalid
+func parseOptionalTime(body map[string]interface{}, key string) *time.Time {
+	str, ok := body[key].(string)
+	if !ok || str == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// parseStringList reads a list of strings from the body, skipping other values
+func parseStringList(body map[string]interface{}, key string) []string {
+	values, _ := body[key].([]interface{})
+	list := []string{}
+	for _, v := range values {
+		if s, ok := v.(string); ok && s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// parseIdentifierKind reads the kind query parameter, isTeam=true stands for teams when kind is not set
+func parseIdentifierKind(query url.Values) (collectionmodels.IdentifierKind, error) {
+	if query.Get("kind") == "" && query.Get("isTeam") == "true" {
+		return collectionmodels.KindTeam, nil
+	}
+	return collectionmodels.ParseIdentifierKind(query.Get("kind"))
+}
+
+// performancePoints is db.GetPerformancePointsBatch taking projects by name, alias or ID.
+// Results keep the identifiers as requested.
+func performancePoints(identifiers []string, startDate, endDate time.Time, kind collectionmodels.IdentifierKind, granularity calendar.Granularity) ([]db.PerformancePointTotalWithTime, error) {
+	client := db.GetMongoClient()
+	dbName := db.GetDatabaseName()
+	collections := db.GetCollections()
+	if kind != collectionmodels.KindProject {
+		return db.GetPerformancePointsBatch(client, dbName, collections, identifiers, startDate, endDate, kind, granularity)
+	}
+	projectIDs, err := db.ProjectIdentifiers(client, dbName, identifiers)
+	if err != nil {
+		return nil, err
+	}
+	// A name and its alias resolve to the same project, which is computed once and reported under each label
+	var unique []string
+	for _, id := range projectIDs {
+		if !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	results, err := db.GetPerformancePointsBatch(client, dbName, collections, unique, startDate, endDate, kind, granularity)
+	if err != nil {
+		return nil, err
+	}
+	byProject := make(map[string][]db.PerformancePointTotalWithTime, len(unique))
+	for _, r := range results {
+		id := r.TotalPerformancePoint.Identifier
+		byProject[id] = append(byProject[id], r)
+	}
+	labeled := make([]db.PerformancePointTotalWithTime, 0, len(results))
+	for i, id := range projectIDs {
+		for _, r := range byProject[id] {
+			r.TotalPerformancePoint.Identifier = identifiers[i]
+			labeled = append(labeled, r)
+		}
+	}
+	return labeled, nil
+}
+
+// writePage encodes a list response, passing the next page token in the X-Next-Cursor header
+func writePage(w http.ResponseWriter, items interface{}, nextCursor string) {
+	if nextCursor != "" {
+		w.Header().Set("X-Next-Cursor", nextCursor)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// writeListError maps query spec errors to 400 and everything else to 500
+func writeListError(w http.ResponseWriter, err error) {
+	if errors.Is(err, collectionmodels.ErrInvalidCursor) || errors.Is(err, collectionmodels.ErrInvalidSortField) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeDatabaseError(w, err)
+}
+
+// writeDatabaseError maps missing records to 404, duplicate keys, overlapping targets and closed weeks to 409 and everything else to 500
+func writeDatabaseError(w http.ResponseWriter, err error) {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		http.Error(w, "Conflict: a record with the same key already exists", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrTargetOverlap) {
+		http.Error(w, "Conflict: "+err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrInvalidDateRange) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrWeekClosed) || errors.Is(err, collectionmodels.ErrWeekAlreadyClosed) {
+		http.Error(w, "Conflict: "+err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrWeekNotEnded) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrAdjustmentReviewed) {
+		http.Error(w, "Conflict: "+err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrInvalidAdjustmentCategory) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrDisputeOpen) || errors.Is(err, collectionmodels.ErrDisputeResolved) {
+		http.Error(w, "Conflict: "+err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrUnknownAssetType) || errors.Is(err, collectionmodels.ErrInvalidOrderItem) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrUnknownProject) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrProjectNameTaken) {
+		http.Error(w, "Conflict: "+err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrProjectAssignmentExists) || errors.Is(err, collectionmodels.ErrProjectAssignmentConflict) {
+		http.Error(w, "Conflict: "+err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, collectionmodels.ErrProjectAssignmentNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, db.ErrInvalidScoring) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+}
+
+func containsAny(slice []string, values []string) bool {
+	for _, v := range values {
+		if contains(slice, v) {
+			return true
+		}
+	}
+	return false
+}
+
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	body := map[string]string{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -183,11 +419,11 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	email := body["email"]
 
-	isInDatabase, err := db.IsEmailInDatabase(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), email)
+	isInDatabase, err := db.IsEmailInDatabase(db.GetDatabaseName(), db.GetCollections().StaffMember, email)
 
 	if err == nil && isInDatabase {
 
-		teamRoles, _ := db.GetMemberRoles(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), email)
+		teamRoles, _ := db.GetMemberRoles(db.GetDatabaseName(), db.GetCollections().StaffMember, email)
 		// Set session data
 		// use bcrypt
 		hash := sha256.New()
@@ -256,7 +492,7 @@ func HandleLastWeekTeamPerformance(w http.ResponseWriter, r *http.Request) {
 	if isAdmin {
 		var err error
 		var tempTeams []*db.Team
-		tempTeams, err = db.GetAllTeams(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"))
+		tempTeams, err = db.GetAllTeams(db.GetDatabaseName(), db.GetCollections().StaffMember, time.Now())
 		if err != nil {
 			log.Println("Error getting all teams:", err)
 			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
@@ -268,26 +504,13 @@ func HandleLastWeekTeamPerformance(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Tính toán startDate là 0 giờ thứ 3 tuần trước, endDate là trước nửa đêm thứ 2 tuần này
-	now := time.Now()
-	// Tìm thứ 2 tuần này
-	monday := now.AddDate(0, 0, -int(now.Weekday())+1)
-	// End time là trước nửa đêm thứ 2 (23:59:59)
-	endDate := time.Date(monday.Year(), monday.Month(), monday.Day(), 23, 59, 59, 0, monday.Location())
-	// Start time là 0 giờ thứ 3 tuần trước
-	lastWeekTuesday := monday.AddDate(0, 0, -6) // Thứ 3 tuần trước
-	startDate := time.Date(lastWeekTuesday.Year(), lastWeekTuesday.Month(), lastWeekTuesday.Day(), 0, 0, 0, 0, lastWeekTuesday.Location())
-	var results []db.PerformancePointTotalWithTime
-	if len(teams) > 0 {
-		for _, team := range teams {
-			res, err := db.GetPerformancePoints(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), team, startDate, endDate, true, false)
-			if err != nil {
-				http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
-				log.Println("Database error:", err)
-				return
-			}
-			results = append(results, res...)
-		}
+	// Tuần gần nhất đã kết thúc (hoặc kết thúc hôm nay), theo múi giờ và ngày bắt đầu tuần đã cấu hình
+	startDate, endDate := calendar.LastClosedWeek(time.Now())
+	results, err := db.GetPerformancePointsBatch(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections(), teams, startDate, endDate, collectionmodels.KindTeam, calendar.WholeRange)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		log.Println("Database error:", err)
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(results)
@@ -320,8 +543,8 @@ func HandleTeamWeeklyTarget(w http.ResponseWriter, r *http.Request) {
 		var err error
 		var tempTeams []*db.Team
 		// log out the URLm and DB name, and collection name
-		//log.Printf("Getting all teams from DB: %s, Collection: %s", os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"))
-		tempTeams, err = db.GetAllTeams(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"))
+		//log.Printf("Getting all teams from DB: %s, Collection: %s", db.GetDatabaseName(), db.GetCollections().StaffMember)
+		tempTeams, err = db.GetAllTeams(db.GetDatabaseName(), db.GetCollections().StaffMember, time.Now())
 		if err != nil {
 			log.Println("Error getting all teams:", err)
 			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
@@ -336,7 +559,7 @@ func HandleTeamWeeklyTarget(w http.ResponseWriter, r *http.Request) {
 	var results []*db.TeamWeeklyTarget
 	if len(teams) > 0 {
 		for _, team := range teams {
-			res, err := db.GetTeamWeeklyTarget(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TEAM_WEEKLY_TARGET"), team)
+			res, err := db.GetTeamWeeklyTarget(db.GetDatabaseName(), team)
 			if err != nil {
 				http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 				log.Println("Database error:", err)
@@ -365,15 +588,40 @@ func HandleAddNewTeamMember(w http.ResponseWriter, r *http.Request) {
 		Name:     body["Name"].(string),
 		YOB:      int(body["YOB"].(float64)),
 		Email:    body["Email"].(string),
-		Role:     body["Role"].(string),
-		Team:     body["Team"].(string),
+		JoinedAt: parseOptionalTime(body, "JoinedAt"),
+	}
+	if workPercent, ok := body["WorkPercent"].(float64); ok {
+		member.WorkPercent = int(workPercent)
+	}
+
+	// Memberships start on the joining date, or are open ended when it is unknown
+	var from time.Time
+	if member.JoinedAt != nil {
+		from = *member.JoinedAt
+	}
+	// Accept a single Team/Role pair or a Memberships list of them
+	if team, ok := body["Team"].(string); ok && team != "" {
+		role, _ := body["Role"].(string)
+		member.Assignments = append(member.Assignments, collectionmodels.TeamAssignment{Team: team, Role: role, From: from})
+	}
+	if memberships, ok := body["Memberships"].([]interface{}); ok {
+		for _, m := range memberships {
+			entry, ok := m.(map[string]interface{})
+			if !ok {
+				http.Error(w, "Invalid Memberships", http.StatusBadRequest)
+				return
+			}
+			team, _ := entry["Team"].(string)
+			role, _ := entry["Role"].(string)
+			member.Assignments = append(member.Assignments, collectionmodels.TeamAssignment{Team: team, Role: role, From: from})
+		}
 	}
 
 	log.Println("Adding new member:", member)
 
-	err := collectionmodels.InsertMemberToDataBase(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), member)
+	err := collectionmodels.InsertMemberToDataBase(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().StaffMember, member)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -384,13 +632,17 @@ func HandleGetAllTeamMembers(w http.ResponseWriter, r *http.Request) {
 
 	// TOOD : implement role-based access control
 
-	res, err := collectionmodels.GetAllMembers(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"))
+	spec, err := parseQuerySpec(r)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(res)
+	res, nextCursor, err := collectionmodels.GetMembersPage(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections(), spec)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, res, nextCursor)
 }
 
 func HandleUpdateTeamMember(w http.ResponseWriter, r *http.Request) {
@@ -401,20 +653,55 @@ func HandleUpdateTeamMember(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
+	memberID, idOK := body["MemberID"].(string)
+	name, nameOK := body["Name"].(string)
+	yob, yobOK := body["YOB"].(float64)
+	email, emailOK := body["Email"].(string)
+	if !idOK || !nameOK || !yobOK || !emailOK || memberID == "" {
+		http.Error(w, "MemberID, Name, YOB and Email are required", http.StatusBadRequest)
+		return
+	}
 	member := &collectionmodels.Member{
-		MemberID: body["MemberID"].(string),
-		Name:     body["Name"].(string),
-		YOB:      int(body["YOB"].(float64)),
-		Email:    body["Email"].(string),
-		Role:     body["Role"].(string),
-		Team:     body["Team"].(string),
+		MemberID: memberID,
+		Name:     name,
+		YOB:      int(yob),
+		Email:    email,
+		JoinedAt: parseOptionalTime(body, "JoinedAt"),
+		LeftAt:   parseOptionalTime(body, "LeftAt"),
+	}
+	if workPercent, ok := body["WorkPercent"].(float64); ok {
+		member.WorkPercent = int(workPercent)
 	}
 
-	err := collectionmodels.UpdateMemberToDataBase(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), member)
+	// Leaving ends the member's memberships, which changes the team scores from that date on
+	if member.LeftAt != nil {
+		stored, err := collectionmodels.GetMemberHistory(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().StaffMember, member.MemberID)
+		if err != nil {
+			writeDatabaseError(w, err)
+			return
+		}
+		var teams []string
+		for _, a := range stored.Assignments {
+			if (a.To == nil || a.To.After(*member.LeftAt)) && !slices.Contains(teams, a.Team) {
+				teams = append(teams, a.Team)
+			}
+		}
+		if err := checkTeamWeeksOpen(teams, *member.LeftAt, nil); err != nil {
+			writeDatabaseError(w, err)
+			return
+		}
+	}
+	err := collectionmodels.UpdateMemberToDataBase(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().StaffMember, member)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
+	if member.LeftAt != nil {
+		if err := db.InvalidateTeamSnapshots(db.GetMongoClient(), db.GetDatabaseName(), *member.LeftAt); err != nil {
+			writeDatabaseError(w, err)
+			return
+		}
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"message": "Member updated successfully"}`))
 }
@@ -429,397 +716,1264 @@ func HandleDeleteTeamMember(w http.ResponseWriter, r *http.Request) {
 	}
 	memberID := body["MemberID"].(string)
 	log.Println("Deleting member with ID:", memberID)
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
 
-	err := collectionmodels.DeleteMemberInDataBase(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), memberID)
+	err := collectionmodels.DeleteMemberInDataBase(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().StaffMember, memberID, deletedBy)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"message": "Member deleted successfully"}`))
 }
 
-/// =========== End Team Members Handler ================
-/// =====================================================
-
-/// =====================================================
-/// ============ Project Details Handler ================
-
-func HandleAddNewProjectDetail(w http.ResponseWriter, r *http.Request) {
+func HandleTransferTeamMember(w http.ResponseWriter, r *http.Request) {
 	// TOOD : implement role-based access control
+
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	projectDetail := &collectionmodels.ProjectDetail{
-		ProjectID: int(body["ProjectID"].(float64)),
-		Project:   body["Project"].(string),
-		Research:  body["Research"].(string),
-		Art:       body["Art"].(string),
-		Concept:   body["Concept"].(string),
-		Video:     body["Video"].(string),
-		Pla:       body["Pla"].(string),
-		UA:        body["UA"].(string),
+	memberID, _ := body["MemberID"].(string)
+	fromTeam, _ := body["FromTeam"].(string)
+	team, _ := body["Team"].(string)
+	role, _ := body["Role"].(string)
+	if memberID == "" || fromTeam == "" || team == "" || role == "" {
+		http.Error(w, "MemberID, FromTeam, Team and Role are required", http.StatusBadRequest)
+		return
 	}
-
-	err := collectionmodels.InstertNewProjectDetailToDatabase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), projectDetail)
-	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+	effective := parseOptionalTime(body, "EffectiveDate")
+	if effective == nil {
+		http.Error(w, "Invalid EffectiveDate", http.StatusBadRequest)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message": "Project detail added successfully"}`))
-}
-
-func HandleGetAllProjectDetails(w http.ResponseWriter, r *http.Request) {
-	// TODO : implement role-based access control
 
-	res, err := collectionmodels.GetAllProjectDetails(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"))
+	// Moving a member changes the team scores from that date on
+	if err := checkTeamWeeksOpen([]string{fromTeam, team}, *effective, nil); err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	err := collectionmodels.TransferMember(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().StaffMember, memberID, fromTeam, team, role, *effective)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeMembershipError(w, err)
+		return
+	}
+	if err := db.InvalidateTeamSnapshots(db.GetMongoClient(), db.GetDatabaseName(), *effective); err != nil {
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(res)
+	w.Write([]byte(`{"message": "Member transferred successfully"}`))
 }
 
-func HandleUpdateProjectDetail(w http.ResponseWriter, r *http.Request) {
+func HandleAddMembership(w http.ResponseWriter, r *http.Request) {
 	// TOOD : implement role-based access control
+
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	projectDetail := &collectionmodels.ProjectDetail{
-		ProjectID: int(body["ProjectID"].(float64)),
-		Project:   body["Project"].(string),
-		Research:  body["Research"].(string),
-		Art:       body["Art"].(string),
-		Concept:   body["Concept"].(string),
-		Video:     body["Video"].(string),
-		Pla:       body["Pla"].(string),
-		UA:        body["UA"].(string),
+	memberID, _ := body["MemberID"].(string)
+	team, _ := body["Team"].(string)
+	role, _ := body["Role"].(string)
+	if memberID == "" || team == "" || role == "" {
+		http.Error(w, "MemberID, Team and Role are required", http.StatusBadRequest)
+		return
 	}
-	err := collectionmodels.UpdateProjectDetailToDatabase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), projectDetail)
+	from := time.Now()
+	if t := parseOptionalTime(body, "From"); t != nil {
+		from = *t
+	}
+
+	// Moving a member changes the team scores from that date on
+	if err := checkTeamWeeksOpen([]string{team}, from, nil); err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	err := collectionmodels.AddMembership(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().StaffMember, memberID, team, role, from)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeMembershipError(w, err)
+		return
+	}
+	if err := db.InvalidateTeamSnapshots(db.GetMongoClient(), db.GetDatabaseName(), from); err != nil {
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message": "Project detail updated successfully"}`))
+	w.Write([]byte(`{"message": "Membership added successfully"}`))
 }
 
-func HandleDeleteProjectDetail(w http.ResponseWriter, r *http.Request) {
+func HandleRemoveMembership(w http.ResponseWriter, r *http.Request) {
 	// TOOD : implement role-based access control
+
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	projectID := body["Project"].(string)
-	err := collectionmodels.DeleteProjectDetailInDatabase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), projectID)
+	memberID, _ := body["MemberID"].(string)
+	team, _ := body["Team"].(string)
+	if memberID == "" || team == "" {
+		http.Error(w, "MemberID and Team are required", http.StatusBadRequest)
+		return
+	}
+	at := time.Now()
+	if t := parseOptionalTime(body, "To"); t != nil {
+		at = *t
+	}
+
+	// Moving a member changes the team scores from that date on
+	if err := checkTeamWeeksOpen([]string{team}, at, nil); err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	err := collectionmodels.RemoveMembership(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().StaffMember, memberID, team, at)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeMembershipError(w, err)
+		return
+	}
+	if err := db.InvalidateTeamSnapshots(db.GetMongoClient(), db.GetDatabaseName(), at); err != nil {
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message": "Project detail deleted successfully"}`))
+	w.Write([]byte(`{"message": "Membership removed successfully"}`))
 }
 
-/// =========== End Project Detail Handler ================
-/// =======================================================
-
-/// =======================================================
-/// =========== Creative Tool Handler =====================
-
-func HandleGetAllCreativeTools(w http.ResponseWriter, r *http.Request) {
+func writeMembershipError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, collectionmodels.ErrInvalidTransferDate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, collectionmodels.ErrMembershipExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, collectionmodels.ErrMembershipNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		writeDatabaseError(w, err)
+	}
+}
 
+func HandleGetMemberHistory(w http.ResponseWriter, r *http.Request) {
 	// TOOD : implement role-based access control
-	res, err := collectionmodels.GetAllCreativeTools(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"))
+
+	memberID := r.URL.Query().Get("memberID")
+	member, err := collectionmodels.GetMemberHistory(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().StaffMember, memberID)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(res)
+	json.NewEncoder(w).Encode(member)
 }
 
-func HandleUpdateCreativeTool(w http.ResponseWriter, r *http.Request) {
+func HandleRestoreTeamMember(w http.ResponseWriter, r *http.Request) {
 	// TOOD : implement role-based access control
+
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	pointsInterface := body["Point"].([]interface{})
-	points := make([]float64, len(pointsInterface))
-	for i, v := range pointsInterface {
-		points[i] = v.(float64)
-	}
-	tool := &collectionmodels.CreativeTool{
-		Team:     body["Team"].(string),
-		ToolName: body["ToolName"].(string),
-		Type:     body["Type"].(string),
-		Point:    points,
+	memberID, ok := body["MemberID"].(string)
+	if !ok || memberID == "" {
+		http.Error(w, "MemberID is required", http.StatusBadRequest)
+		return
 	}
-	err := collectionmodels.UpdateCreativeTool(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), tool)
+
+	err := collectionmodels.RestoreMemberInDataBase(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().StaffMember, memberID)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message": "Creative tool updated successfully"}`))
+	w.Write([]byte(`{"message": "Member restored successfully"}`))
 }
 
-func HandleAddNewCreativeTool(w http.ResponseWriter, r *http.Request) {
+/// =========== End Team Members Handler ================
+/// =====================================================
+
+/// =====================================================
+/// ============ Project Details Handler ================
+
+func HandleAddNewProjectDetail(w http.ResponseWriter, r *http.Request) {
 	// TOOD : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	pointsInterface := body["Point"].([]interface{})
-	points := make([]float64, len(pointsInterface))
-	for i, v := range pointsInterface {
-		points[i] = v.(float64)
+	// ProjectID is given the next free ID when missing
+	projectID, _ := body["ProjectID"].(float64)
+	projectDetail := &collectionmodels.ProjectDetail{
+		ProjectID: int(projectID),
+		Project:   body["Project"].(string),
+		Aliases:   parseStringList(body, "Aliases"),
 	}
-	tool := &collectionmodels.CreativeTool{
-		Team:     body["Team"].(string),
-		ToolName: body["ToolName"].(string),
-		Type:     body["Type"].(string),
-		Point:    points,
+
+	err := collectionmodels.InstertNewProjectDetailToDatabase(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ProjectDetail, projectDetail)
+	if err == nil {
+		// Tasks synced before the project was added now resolve to it
+		_, err = db.ResolveTaskProjects(db.GetMongoClient(), db.GetDatabaseName())
 	}
-	err := collectionmodels.AddCreativeTool(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), tool)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message": "Creative tool added successfully"}`))
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": "Project detail added successfully", "ProjectID": projectDetail.ProjectID})
 }
 
-func HandleDeleteCreativeTool(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
-	var body map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+func HandleGetAllProjectDetails(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+
+	spec, err := parseQuerySpec(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res, nextCursor, err := collectionmodels.GetProjectDetailsPage(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ProjectDetail, spec)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, res, nextCursor)
+}
+
+func HandleUpdateProjectDetail(w http.ResponseWriter, r *http.Request) {
+	// TOOD : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	// The project is found by ProjectID, a new Project renames it
+	projectDetail := &collectionmodels.ProjectDetail{
+		ProjectID: int(body["ProjectID"].(float64)),
+		Project:   body["Project"].(string),
+	}
+	err := db.RenameProject(db.GetMongoClient(), db.GetDatabaseName(), projectDetail)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Project detail updated successfully"}`))
+}
+
+func HandleDeleteProjectDetail(w http.ResponseWriter, r *http.Request) {
+	// TOOD : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	projectID := body["Project"].(string)
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	err := collectionmodels.DeleteProjectDetailInDatabase(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ProjectDetail, projectID, deletedBy)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Project detail deleted successfully"}`))
+}
+
+func HandleRestoreProjectDetail(w http.ResponseWriter, r *http.Request) {
+	// TOOD : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	projectID, ok := body["Project"].(string)
+	if !ok || projectID == "" {
+		http.Error(w, "Project is required", http.StatusBadRequest)
+		return
+	}
+	err := collectionmodels.RestoreProjectDetailInDatabase(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ProjectDetail, projectID)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Project detail restored successfully"}`))
+}
+
+// HandleAddProjectAlias lets a project be found by another name. Body: ProjectID, Alias.
+// Completed tasks of that name are linked to the project.
+func HandleAddProjectAlias(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	projectID, _ := body["ProjectID"].(float64)
+	alias, _ := body["Alias"].(string)
+	if projectID == 0 || alias == "" {
+		http.Error(w, "ProjectID and Alias are required", http.StatusBadRequest)
+		return
+	}
+	var resolved int64
+	err := collectionmodels.AddProjectAlias(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ProjectDetail, int(projectID), alias)
+	if err == nil {
+		resolved, err = db.ResolveTaskProjects(db.GetMongoClient(), db.GetDatabaseName())
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": "Project alias added successfully", "ResolvedTasks": resolved})
+}
+
+// HandleRemoveProjectAlias stops resolving a name to a project. Body: ProjectID, Alias.
+func HandleRemoveProjectAlias(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	projectID, _ := body["ProjectID"].(float64)
+	alias, _ := body["Alias"].(string)
+	err := collectionmodels.RemoveProjectAlias(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ProjectDetail, int(projectID), alias)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Project alias removed successfully"}`))
+}
+
+// HandleGetUnknownProjects lists the project names of completed tasks that match no project of the catalog,
+// to add them as projects or aliases
+func HandleGetUnknownProjects(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	res, err := collectionmodels.GetUnresolvedTaskProjects(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().CompletedTask)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// HandleAddProjectAssignment assigns a member to a project, given by name, alias or ID, for a team.
+// Body: Project, Email, Team, Role (owner by default), From (now by default).
+func HandleAddProjectAssignment(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	project, _ := body["Project"].(string)
+	assignment := collectionmodels.ProjectAssignment{Role: collectionmodels.ProjectRoleOwner, From: time.Now()}
+	assignment.Email, _ = body["Email"].(string)
+	assignment.Team, _ = body["Team"].(string)
+	if project == "" || assignment.Email == "" || assignment.Team == "" {
+		http.Error(w, "Project, Email and Team are required", http.StatusBadRequest)
+		return
+	}
+	if role, _ := body["Role"].(string); role != "" {
+		assignment.Role = role
+	}
+	if t := parseOptionalTime(body, "From"); t != nil {
+		assignment.From = *t
+	}
+
+	member, err := collectionmodels.GetMemberByEmail(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().StaffMember, assignment.Email)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && member.DeletedAt != nil) {
+		http.Error(w, "Unknown member", http.StatusBadRequest)
+		return
+	}
+	var projectID int
+	if err == nil {
+		projectID, err = db.ResolveProjectID(db.GetMongoClient(), db.GetDatabaseName(), project)
+	}
+	if err == nil {
+		err = collectionmodels.AddProjectAssignment(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ProjectDetail, projectID, assignment)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Project assignment added successfully"}`))
+}
+
+// HandleEndProjectAssignment ends the ongoing assignment of a member to a project, given by name, alias or ID,
+// for a team.
+// Body: Project, Email, Team, To (now by default).
+func HandleEndProjectAssignment(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	project, _ := body["Project"].(string)
+	email, _ := body["Email"].(string)
+	team, _ := body["Team"].(string)
+	if project == "" || email == "" || team == "" {
+		http.Error(w, "Project, Email and Team are required", http.StatusBadRequest)
+		return
+	}
+	at := time.Now()
+	if t := parseOptionalTime(body, "To"); t != nil {
+		at = *t
+	}
+	projectID, err := db.ResolveProjectID(db.GetMongoClient(), db.GetDatabaseName(), project)
+	if err == nil {
+		err = collectionmodels.EndProjectAssignment(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ProjectDetail, projectID, email, team, at)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Project assignment ended successfully"}`))
+}
+
+// HandlePostProjectOwnership returns who was assigned to a project each week.
+// Body: Project, StartDate, EndDate, Team to only list the assignments of one team.
+func HandlePostProjectOwnership(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	project, _ := body["Project"].(string)
+	team, _ := body["Team"].(string)
+	if project == "" {
+		http.Error(w, "Missing Project", http.StatusBadRequest)
+		return
+	}
+	startDate := parseOptionalTime(body, "StartDate")
+	endDate := parseOptionalTime(body, "EndDate")
+	if startDate == nil || endDate == nil || endDate.Before(*startDate) {
+		http.Error(w, "Invalid StartDate or EndDate", http.StatusBadRequest)
+		return
+	}
+	history, err := db.GetProjectOwnership(db.GetMongoClient(), db.GetDatabaseName(), project, team, *startDate, *endDate)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+/// =========== End Project Detail Handler ================
+/// =======================================================
+
+/// =======================================================
+/// =========== Creative Tool Handler =====================
+
+func HandleGetAllCreativeTools(w http.ResponseWriter, r *http.Request) {
+
+	// TOOD : implement role-based access control
+	res, err := collectionmodels.GetAllCreativeTools(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().CreativeTools, r.URL.Query().Get("includeDeleted") == "true")
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func HandleUpdateCreativeTool(w http.ResponseWriter, r *http.Request) {
+	// TOOD : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	pointsInterface := body["Point"].([]interface{})
+	points := make([]float64, len(pointsInterface))
+	for i, v := range pointsInterface {
+		points[i] = v.(float64)
+	}
+	tool := &collectionmodels.CreativeTool{
+		Team:     body["Team"].(string),
+		ToolName: body["ToolName"].(string),
+		Type:     body["Type"].(string),
+		Point:    points,
+	}
+	err := collectionmodels.UpdateCreativeTool(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().CreativeTools, tool)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Creative tool updated successfully"}`))
+}
+
+func HandleAddNewCreativeTool(w http.ResponseWriter, r *http.Request) {
+	// TOOD : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	pointsInterface := body["Point"].([]interface{})
+	points := make([]float64, len(pointsInterface))
+	for i, v := range pointsInterface {
+		points[i] = v.(float64)
+	}
+	tool := &collectionmodels.CreativeTool{
+		Team:     body["Team"].(string),
+		ToolName: body["ToolName"].(string),
+		Type:     body["Type"].(string),
+		Point:    points,
+	}
+	err := collectionmodels.AddCreativeTool(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().CreativeTools, tool)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Creative tool added successfully"}`))
+}
+
+func HandleDeleteCreativeTool(w http.ResponseWriter, r *http.Request) {
+	// TOOD : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
 	team := body["Team"].(string)
 	toolName := body["ToolName"].(string)
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
 
-	err := collectionmodels.DeleteCreativeTool(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), team, toolName)
+	err := collectionmodels.DeleteCreativeTool(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().CreativeTools, team, toolName, deletedBy)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Creative tool deleted successfully"}`))
+}
+
+func HandleRestoreCreativeTool(w http.ResponseWriter, r *http.Request) {
+	// TOOD : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	team, teamOK := body["Team"].(string)
+	toolName, toolOK := body["ToolName"].(string)
+	if !teamOK || !toolOK || team == "" || toolName == "" {
+		http.Error(w, "Team and ToolName are required", http.StatusBadRequest)
+		return
+	}
+
+	err := collectionmodels.RestoreCreativeTool(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().CreativeTools, team, toolName)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Creative tool restored successfully"}`))
+}
+
+/// =========== End Creative Tool Handler =================
+/// =======================================================
+
+/// =========== Asset Type Handler ========================
+
+func HandleGetAllAssetTypes(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	res, err := collectionmodels.GetAllAssetTypes(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().AssetType, r.URL.Query().Get("includeDeleted") == "true")
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// parseAssetType reads Key, Name, Team and Keyword
+func parseAssetType(body map[string]interface{}) (*collectionmodels.AssetType, error) {
+	assetType := &collectionmodels.AssetType{}
+	assetType.Key, _ = body["Key"].(string)
+	assetType.Name, _ = body["Name"].(string)
+	assetType.Team, _ = body["Team"].(string)
+	assetType.Keyword, _ = body["Keyword"].(string)
+	if assetType.Key == "" || assetType.Team == "" {
+		return nil, errors.New("Key and Team are required")
+	}
+	if assetType.Name == "" {
+		assetType.Name = assetType.Key
+	}
+	return assetType, nil
+}
+
+func HandleAddNewAssetType(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	assetType, err := parseAssetType(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = collectionmodels.AddAssetType(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().AssetType, assetType)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Asset type added successfully"}`))
+}
+
+func HandleUpdateAssetType(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	assetType, err := parseAssetType(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = collectionmodels.UpdateAssetType(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().AssetType, assetType)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Asset type updated successfully"}`))
+}
+
+func HandleDeleteAssetType(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	key, _ := body["Key"].(string)
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	err := collectionmodels.DeleteAssetType(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().AssetType, key, deletedBy)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Asset type deleted successfully"}`))
+}
+
+func HandleRestoreAssetType(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	key, ok := body["Key"].(string)
+	if !ok || key == "" {
+		http.Error(w, "Key is required", http.StatusBadRequest)
+		return
+	}
+	err := collectionmodels.RestoreAssetType(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().AssetType, key)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Asset type restored successfully"}`))
+}
+
+/// =========== End Asset Type Handler ====================
+/// =======================================================
+
+// / =======================================================
+// / ============ Level To Point Handler ===================
+
+func HandleGetAllLevel(w http.ResponseWriter, r *http.Request) {
+
+	// TOOD : implement role-based access control
+	res, err := collectionmodels.GetAllLevels(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().Level, r.URL.Query().Get("includeDeleted") == "true")
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func HandleUpdateLevel(w http.ResponseWriter, r *http.Request) {
+	// TOOD : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	pointsInterface := body["Point"].([]interface{})
+	points := make([]int, len(pointsInterface))
+	for i, v := range pointsInterface {
+		points[i] = int(v.(float64))
+	}
+	level := &collectionmodels.Level{
+		Team:       body["Team"].(string),
+		LevelPoint: points,
+	}
+	err := collectionmodels.UpdateLevelPointsForTeam(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().Level, level)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+}
+
+func HandleAddNewLevel(w http.ResponseWriter, r *http.Request) {
+	// TOOD : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	pointsInterface := body["Point"].([]interface{})
+	points := make([]int, len(pointsInterface))
+	for i, v := range pointsInterface {
+		points[i] = int(v.(float64))
+	}
+	level := &collectionmodels.Level{
+		Team:       body["Team"].(string),
+		LevelPoint: points,
+	}
+	err := collectionmodels.AddNewLevelForTeam(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().Level, level)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "New level added successfully"}`))
+}
+
+func HandleDeleteLevel(w http.ResponseWriter, r *http.Request) {
+	// TOOD : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	team := body["Team"].(string)
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+
+	err := collectionmodels.DeleteLevelForTeam(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().Level, team, deletedBy)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Level deleted successfully"}`))
+}
+
+func HandleRestoreLevel(w http.ResponseWriter, r *http.Request) {
+	// TOOD : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	team, ok := body["Team"].(string)
+	if !ok || team == "" {
+		http.Error(w, "Team is required", http.StatusBadRequest)
+		return
+	}
+
+	err := collectionmodels.RestoreLevelForTeam(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().Level, team)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Level restored successfully"}`))
+}
+
+// / =========== End Level To Point Handler ================
+// / =======================================================
+
+// / =======================================================
+// / ============= Weekly Target Handler ===================
+
+func HandleGetWeeklyTarget(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	spec, err := parseQuerySpec(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	target, nextCursor, err := collectionmodels.GetWeeklyTargetsPage(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyTarget, spec)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, target, nextCursor)
+}
+
+// parseWeeklyTarget reads Team, Point, DateFrom, the optional DateTo and EveryWeeks, and ID when updating
+func parseWeeklyTarget(body map[string]interface{}, withID bool) (*collectionmodels.WeeklyTarget, error) {
+	team, _ := body["Team"].(string)
+	point, ok := body["Point"].(float64)
+	dateFrom := parseOptionalTime(body, "DateFrom")
+	if team == "" || !ok || dateFrom == nil {
+		return nil, errors.New("Team, Point and DateFrom are required")
+	}
+	target := &collectionmodels.WeeklyTarget{
+		Team:     team,
+		Point:    int(point),
+		DateFrom: *dateFrom,
+		// No DateTo keeps the target for good
+		DateTo: parseOptionalTime(body, "DateTo"),
+	}
+	if everyWeeks, ok := body["EveryWeeks"].(float64); ok {
+		if everyWeeks < 0 {
+			return nil, errors.New("EveryWeeks must not be negative")
+		}
+		target.EveryWeeks = int(everyWeeks)
+	}
+	if withID {
+		id, err := parseObjectID(body, "ID")
+		if err != nil {
+			return nil, err
+		}
+		target.ID = id
+	}
+	return target, nil
+}
+
+// parseObjectID reads a hex ObjectID from the body
+func parseObjectID(body map[string]interface{}, key string) (primitive.ObjectID, error) {
+	idStr, _ := body[key].(string)
+	id, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		return id, errors.New("invalid " + key)
+	}
+	return id, nil
+}
+
+func HandleUpdateWeeklyTarget(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	target, err := parseWeeklyTarget(body, true)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = weeklyTargetChangeOpen(target)
+	if err == nil {
+		err = collectionmodels.UpdateWeeklyTarget(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyTarget, target)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message": "Creative tool deleted successfully"}`))
+	w.Write([]byte(`{"message": "Weekly target updated successfully"}`))
 }
 
-/// =========== End Creative Tool Handler =================
-/// =======================================================
-
-// / =======================================================
-// / ============ Level To Point Handler ===================
-
-func HandleGetAllLevel(w http.ResponseWriter, r *http.Request) {
-
-	// TOOD : implement role-based access control
-	res, err := collectionmodels.GetAllLevels(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_LEVEL"))
+func HandleAddNewWeeklyTarget(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	target, err := parseWeeklyTarget(body, false)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = checkTeamWeeksOpen([]string{target.Team}, target.DateFrom, target.DateTo)
+	if err == nil {
+		err = collectionmodels.InsertWeeklyTarget(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyTarget, target)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(res)
+	w.Write([]byte(`{"message": "New weekly target added successfully"}`))
 }
 
-func HandleUpdateLevel(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
+func HandleDeleteWeeklyTarget(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	pointsInterface := body["Point"].([]interface{})
-	points := make([]int, len(pointsInterface))
-	for i, v := range pointsInterface {
-		points[i] = int(v.(float64))
+	id, err := parseObjectID(body, "ID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	level := &collectionmodels.Level{
-		Team:       body["Team"].(string),
-		LevelPoint: points,
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	err = storedWeeklyTargetOpen(id)
+	if err == nil {
+		err = collectionmodels.DeleteWeeklyTarget(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyTarget, id, deletedBy)
 	}
-	err := collectionmodels.UpdateLevelPointsForTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_LEVEL"), level)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Weekly target deleted successfully"}`))
 }
 
-func HandleAddNewLevel(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
+func HandleRestoreWeeklyTarget(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	pointsInterface := body["Point"].([]interface{})
-	points := make([]int, len(pointsInterface))
-	for i, v := range pointsInterface {
-		points[i] = int(v.(float64))
+	id, err := parseObjectID(body, "ID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	level := &collectionmodels.Level{
-		Team:       body["Team"].(string),
-		LevelPoint: points,
+	err = storedWeeklyTargetOpen(id)
+	if err == nil {
+		err = collectionmodels.RestoreWeeklyTarget(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyTarget, id)
 	}
-	err := collectionmodels.AddNewLevelForTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_LEVEL"), level)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message": "New level added successfully"}`))
+	w.Write([]byte(`{"message": "Weekly target restored successfully"}`))
 }
 
-func HandleDeleteLevel(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
+func HandleGetWeeklyTargetOverrides(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	spec, err := parseQuerySpec(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	overrides, nextCursor, err := collectionmodels.GetWeeklyTargetOverridesPage(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyTargetOverride, spec)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, overrides, nextCursor)
+}
+
+// HandleSetWeeklyTargetOverride sets the target of a team for the week containing WeekStart.
+// Body: Team, WeekStart, Point, Reason
+func HandleSetWeeklyTargetOverride(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	team := body["Team"].(string)
+	team, _ := body["Team"].(string)
+	point, ok := body["Point"].(float64)
+	weekStart := parseOptionalTime(body, "WeekStart")
+	if team == "" || !ok || point < 0 || weekStart == nil {
+		http.Error(w, "Team, WeekStart and a non negative Point are required", http.StatusBadRequest)
+		return
+	}
+	reason, _ := body["Reason"].(string)
+	override := &collectionmodels.WeeklyTargetOverride{Team: team, WeekStart: *weekStart, Point: int(point), Reason: reason}
+	if err := checkTeamWeeksOpen([]string{team}, *weekStart, weekStart); err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	err := collectionmodels.SetWeeklyTargetOverride(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyTargetOverride, override)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Weekly target override saved successfully"}`))
+}
 
-	err := collectionmodels.DeleteLevelForTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_LEVEL"), team)
+func HandleDeleteWeeklyTargetOverride(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	id, err := parseObjectID(body, "ID")
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	err = storedWeeklyTargetOverrideOpen(id)
+	if err == nil {
+		err = collectionmodels.DeleteWeeklyTargetOverride(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyTargetOverride, id, deletedBy)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message": "Level deleted successfully"}`))
+	w.Write([]byte(`{"message": "Weekly target override deleted successfully"}`))
 }
 
-// / =========== End Level To Point Handler ================
+// / ============ End Weekly Target Handler =================
 // / =======================================================
 
 // / =======================================================
-// / ============= Weekly Target Handler ===================
+// / ============= Member Target Handler ===================
+
+// parseMemberTarget reads Email, Point, DateFrom and DateTo, and ID when updating
+func parseMemberTarget(body map[string]interface{}, withID bool) (*collectionmodels.MemberTarget, error) {
+	email, _ := body["Email"].(string)
+	point, ok := body["Point"].(float64)
+	dateFrom := parseOptionalTime(body, "DateFrom")
+	dateTo := parseOptionalTime(body, "DateTo")
+	if email == "" || !ok || dateFrom == nil || dateTo == nil {
+		return nil, errors.New("Email, Point, DateFrom and DateTo are required")
+	}
+	target := &collectionmodels.MemberTarget{Email: email, Point: int(point), DateFrom: *dateFrom, DateTo: *dateTo}
+	if withID {
+		id, err := parseObjectID(body, "ID")
+		if err != nil {
+			return nil, err
+		}
+		target.ID = id
+	}
+	return target, nil
+}
 
-func HandleGetWeeklyTarget(w http.ResponseWriter, r *http.Request) {
+func HandleGetMemberTargets(w http.ResponseWriter, r *http.Request) {
 	// TODO : implement role-based access control
-	target, err := collectionmodels.GetAllWeeklyTargets(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"))
+	spec, err := parseQuerySpec(r)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	targets, nextCursor, err := collectionmodels.GetMemberTargetsPage(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().MemberTarget, spec)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, targets, nextCursor)
+}
+
+func HandleAddNewMemberTarget(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	target, err := parseMemberTarget(body, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = checkMemberWeeksOpen(target.Email, target.DateFrom, &target.DateTo)
+	if err == nil {
+		err = collectionmodels.InsertMemberTarget(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().MemberTarget, target)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(target)
+	w.Write([]byte(`{"message": "New member target added successfully"}`))
 }
 
-func HandleUpdateWeeklyTarget(w http.ResponseWriter, r *http.Request) {
+func HandleUpdateMemberTarget(w http.ResponseWriter, r *http.Request) {
 	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	team := body["Team"].(string)
-	point := body["Point"].(float64)
-	dateFromStr := body["DateFrom"].(string)
-	dateToStr := body["DateTo"].(string)
-	target := &collectionmodels.WeeklyTarget{
-		Team:  team,
-		Point: int(point),
-		DateFrom: func() time.Time {
-			t, _ := time.Parse(time.RFC3339, dateFromStr)
-			return t
-		}(),
-		DateTo: func() time.Time {
-			t, _ := time.Parse(time.RFC3339, dateToStr)
-			return t
-		}(),
-	}
-	err := collectionmodels.UpdateWeeklyTargetByTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), target)
+	target, err := parseMemberTarget(body, true)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = storedMemberTargetOpen(target.ID)
+	if err == nil {
+		err = checkMemberWeeksOpen(target.Email, target.DateFrom, &target.DateTo)
+	}
+	if err == nil {
+		err = collectionmodels.UpdateMemberTarget(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().MemberTarget, target)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message": "Weekly target updated successfully"}`))
+	w.Write([]byte(`{"message": "Member target updated successfully"}`))
 }
 
-func HandleAddNewWeeklyTarget(w http.ResponseWriter, r *http.Request) {
+func HandleDeleteMemberTarget(w http.ResponseWriter, r *http.Request) {
 	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	team := body["Team"].(string)
-	point := body["Point"].(float64)
-	dateFromStr := body["DateFrom"].(string)
-	dateToStr := body["DateTo"].(string)
-	target := &collectionmodels.WeeklyTarget{
-		Team:  team,
-		Point: int(point),
-		DateFrom: func() time.Time {
-			t, _ := time.Parse(time.RFC3339, dateFromStr)
-			return t
-		}(),
-		DateTo: func() time.Time {
-			t, _ := time.Parse(time.RFC3339, dateToStr)
-			return t
-		}(),
-	}
-	err := collectionmodels.InsertWeeklyTarget(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), target)
+	id, err := parseObjectID(body, "ID")
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	err = storedMemberTargetOpen(id)
+	if err == nil {
+		err = collectionmodels.DeleteMemberTarget(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().MemberTarget, id, deletedBy)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message": "New weekly target added successfully"}`))
+	w.Write([]byte(`{"message": "Member target deleted successfully"}`))
 }
 
-func HandleDeleteWeeklyTarget(w http.ResponseWriter, r *http.Request) {
+func HandleRestoreMemberTarget(w http.ResponseWriter, r *http.Request) {
 	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	team := body["Team"].(string)
-	dateFromStr := body["DateFrom"].(string)
-	dateToStr := body["DateTo"].(string)
-	dateFrom, _ := time.Parse(time.RFC3339, dateFromStr)
-	dateTo, _ := time.Parse(time.RFC3339, dateToStr)
-	err := collectionmodels.DeleteWeeklyTarget(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), team, dateFrom, dateTo)
+	id, err := parseObjectID(body, "ID")
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = storedMemberTargetOpen(id)
+	if err == nil {
+		err = collectionmodels.RestoreMemberTarget(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().MemberTarget, id)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message": "Weekly target deleted successfully"}`))
+	w.Write([]byte(`{"message": "Member target restored successfully"}`))
 }
 
-// / ============ End Weekly Target Handler =================
+func HandleGetTargetWeights(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	weights, err := collectionmodels.GetAllTargetWeights(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().TargetWeight)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(weights)
+}
+
+// HandleUpdateTargetWeight sets the split weights of a team, an empty Team sets the default.
+// Body: Team, RoleWeights {role: weight}, SeniorityWeights [{MinYears, Weight}]
+func HandleUpdateTargetWeight(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body struct {
+		Team             string
+		RoleWeights      map[string]float64
+		SeniorityWeights []struct {
+			MinYears float64
+			Weight   float64
+		}
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	weight := &collectionmodels.TargetWeight{Team: body.Team, RoleWeights: body.RoleWeights}
+	for _, s := range body.SeniorityWeights {
+		if s.Weight < 0 || s.MinYears < 0 {
+			http.Error(w, "Seniority weights must not be negative", http.StatusBadRequest)
+			return
+		}
+		weight.SeniorityWeights = append(weight.SeniorityWeights, collectionmodels.SeniorityWeight{MinYears: s.MinYears, Weight: s.Weight})
+	}
+	for role, rw := range body.RoleWeights {
+		if rw < 0 {
+			http.Error(w, "Invalid weight for role "+role, http.StatusBadRequest)
+			return
+		}
+	}
+	err := collectionmodels.UpsertTargetWeight(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().TargetWeight, weight)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Target weights updated successfully"}`))
+}
+
+// / ============ End Member Target Handler =================
 // / =======================================================
 
 /// ============== Weekly Order Handler ===================
 
+// parseOrderItems reads Items, each with AssetType, Team, Quantity, Priority and Notes,
+// and checks them against the asset type catalog
+func parseOrderItems(body map[string]interface{}) ([]collectionmodels.OrderItem, error) {
+	itemsInterface, _ := body["Items"].([]interface{})
+	items := make([]collectionmodels.OrderItem, 0, len(itemsInterface))
+	for _, v := range itemsInterface {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, collectionmodels.ErrInvalidOrderItem
+		}
+		item := collectionmodels.OrderItem{}
+		item.AssetType, _ = m["AssetType"].(string)
+		item.Team, _ = m["Team"].(string)
+		quantity, _ := m["Quantity"].(float64)
+		item.Quantity = int(quantity)
+		item.Priority, _ = m["Priority"].(string)
+		item.Notes, _ = m["Notes"].(string)
+		items = append(items, item)
+	}
+	catalog, err := collectionmodels.GetAllAssetTypes(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().AssetType, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := collectionmodels.ResolveOrderItems(catalog, items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 func HandleGetWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 	// TODO : implement role-based access control
 
-	res, err := collectionmodels.GetAllWeeklyOrders(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"))
+	spec, err := parseQuerySpec(r)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(res)
+	res, nextCursor, err := collectionmodels.GetWeeklyOrdersPage(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyOrder, spec)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, res, nextCursor)
 }
 
 func HandleUpdateWeeklyOrder(w http.ResponseWriter, r *http.Request) {
@@ -833,22 +1987,26 @@ func HandleUpdateWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 
 	startWeekStr := body["StartWeek"].(string)
 	startWeek, _ := time.Parse(time.RFC3339, startWeekStr)
+	items, err := parseOrderItems(body)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
 
 	order := &collectionmodels.WeeklyOrder{
 		StartWeek: startWeek,
 		Goal:      body["Goal"].(string),
 		Strategy:  body["Strategy"].(string),
 		Project:   body["Project"].(string),
-		CPP:       (int)(body["CPP"].(float64)),
-		Icon:      (int)(body["Icon"].(float64)),
-		Banner:    (int)(body["Banner"].(float64)),
-		Video:     (int)(body["Video"].(float64)),
-		PLA:       (int)(body["PLA"].(float64)),
+		Items:     items,
 	}
 
-	err := collectionmodels.UpdateWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"), order)
+	err = db.ResolveOrderProject(db.GetMongoClient(), db.GetDatabaseName(), order)
+	if err == nil {
+		err = collectionmodels.UpdateWeeklyOrder(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyOrder, order)
+	}
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -864,20 +2022,24 @@ func HandleAddNewWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 	}
 	startWeekStr := body["StartWeek"].(string)
 	startWeek, _ := time.Parse(time.RFC3339, startWeekStr)
+	items, err := parseOrderItems(body)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
 	order := &collectionmodels.WeeklyOrder{
 		StartWeek: startWeek,
 		Goal:      body["Goal"].(string),
 		Strategy:  body["Strategy"].(string),
 		Project:   body["Project"].(string),
-		CPP:       (int)(body["CPP"].(float64)),
-		Icon:      (int)(body["Icon"].(float64)),
-		Banner:    (int)(body["Banner"].(float64)),
-		Video:     (int)(body["Video"].(float64)),
-		PLA:       (int)(body["PLA"].(float64)),
+		Items:     items,
+	}
+	err = db.ResolveOrderProject(db.GetMongoClient(), db.GetDatabaseName(), order)
+	if err == nil {
+		err = collectionmodels.InsertWeeklyOrder(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyOrder, order)
 	}
-	err := collectionmodels.InsertWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"), order)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -894,17 +2056,49 @@ func HandleDeleteWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 
 	startWeekStr := body["StartWeek"].(string)
 	startWeek, _ := time.Parse(time.RFC3339, startWeekStr)
-	project := body["Project"].(string)
+	order := &collectionmodels.WeeklyOrder{Project: body["Project"].(string)}
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
 
-	err := collectionmodels.DeleteWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"), startWeek, project)
+	err := db.ResolveOrderProject(db.GetMongoClient(), db.GetDatabaseName(), order)
+	if err == nil {
+		err = collectionmodels.DeleteWeeklyOrder(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyOrder, startWeek, order.ProjectID, deletedBy)
+	}
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"message": "Weekly order deleted successfully"}`))
 }
 
+func HandleRestoreWeeklyOrder(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	startWeek := parseOptionalTime(body, "StartWeek")
+	project, ok := body["Project"].(string)
+	if startWeek == nil || !ok || project == "" {
+		http.Error(w, "StartWeek and Project are required", http.StatusBadRequest)
+		return
+	}
+	order := &collectionmodels.WeeklyOrder{Project: project}
+
+	err := db.ResolveOrderProject(db.GetMongoClient(), db.GetDatabaseName(), order)
+	if err == nil {
+		err = collectionmodels.RestoreWeeklyOrder(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyOrder, *startWeek, order.ProjectID)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Weekly order restored successfully"}`))
+}
+
 /// =======================================================
 
 /// ========================================================
@@ -923,9 +2117,9 @@ func HandlePostProjectIssues(w http.ResponseWriter, r *http.Request) {
 	endWeekStr := body["EndDate"].(string)
 	endWeek, _ := time.Parse(time.RFC3339, endWeekStr)
 
-	issues, err := collectionmodels.GetProjectIssues(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"), startWeek, endWeek)
+	issues, err := collectionmodels.GetProjectIssues(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections(), startWeek, endWeek)
 	if err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		writeDatabaseError(w, err)
 		return
 	}
 
@@ -933,48 +2127,173 @@ func HandlePostProjectIssues(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(issues)
 }
 
+// HandlePostOrderReconciliation compares weekly orders with completed tasks per project, team and asset type,
+// with carry-over of unfinished orders and the weekly fulfilment trend.
+// Body: StartDate, EndDate, Projects (optional), Teams (optional)
+func HandlePostOrderReconciliation(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	startDate := parseOptionalTime(body, "StartDate")
+	endDate := parseOptionalTime(body, "EndDate")
+	if startDate == nil || endDate == nil || endDate.Before(*startDate) {
+		http.Error(w, "Invalid StartDate or EndDate", http.StatusBadRequest)
+		return
+	}
+	var filters [2][]string
+	for i, key := range []string{"Projects", "Teams"} {
+		values, _ := body[key].([]interface{})
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				filters[i] = append(filters[i], s)
+			}
+		}
+	}
+
+	report, err := db.GetOrderReconciliation(db.GetMongoClient(), db.GetDatabaseName(), filters[0], filters[1], *startDate, *endDate)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandlePostProjectPerformance reports the points, task counts and levels of completed tasks per project, team and week,
+// with the totals of each project.
+// Body: StartDate, EndDate, Projects (optional, by name, alias or ID), Teams (optional)
+func HandlePostProjectPerformance(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	startDate := parseOptionalTime(body, "StartDate")
+	endDate := parseOptionalTime(body, "EndDate")
+	if startDate == nil || endDate == nil || endDate.Before(*startDate) {
+		http.Error(w, "Invalid StartDate or EndDate", http.StatusBadRequest)
+		return
+	}
+
+	report, err := db.GetProjectPerformance(db.GetMongoClient(), db.GetDatabaseName(), parseStringList(body, "Projects"), parseStringList(body, "Teams"), *startDate, *endDate)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
 /// =========== End Project Issues Handler =================
 /// ========================================================
 
-func Init() {
+func Init(cfg *config.Config) {
+	settings = *cfg
+
 	http.Handle("/login", CORSMiddleware(http.HandlerFunc(LoginHandler)))
 
 	http.Handle("/post/performance-point", CORSMiddleware(http.HandlerFunc(PostHandlerPerformancePoint)))
 	http.Handle("/post/staff-member", CORSMiddleware(http.HandlerFunc(PostHandlerStaffMember)))
 	http.Handle("/get/last-week-team-performance", CORSMiddleware(http.HandlerFunc(HandleLastWeekTeamPerformance)))
 	http.Handle("/get/team-weekly-target", CORSMiddleware(http.HandlerFunc(HandleTeamWeeklyTarget)))
+	http.Handle("/post/target-attainment", CORSMiddleware(http.HandlerFunc(HandleTargetAttainment)))
+	http.Handle("/post/rebuild-performance-snapshots", CORSMiddleware(http.HandlerFunc(HandleRebuildPerformanceSnapshots)))
+	http.Handle("/get/week-closures", CORSMiddleware(http.HandlerFunc(HandleGetWeekClosures)))
+	http.Handle("/post/close-week", CORSMiddleware(http.HandlerFunc(HandleCloseWeek)))
+	http.Handle("/post/reopen-week", CORSMiddleware(http.HandlerFunc(HandleReopenWeek)))
+	http.Handle("/get/score-adjustments", CORSMiddleware(http.HandlerFunc(HandleGetScoreAdjustments)))
+	http.Handle("/post/add-new-score-adjustment", CORSMiddleware(http.HandlerFunc(HandleAddNewScoreAdjustment)))
+	http.Handle("/post/update-score-adjustment", CORSMiddleware(http.HandlerFunc(HandleUpdateScoreAdjustment)))
+	http.Handle("/post/delete-score-adjustment", CORSMiddleware(http.HandlerFunc(HandleDeleteScoreAdjustment)))
+	http.Handle("/post/review-score-adjustment", CORSMiddleware(http.HandlerFunc(HandleReviewScoreAdjustment)))
+	http.Handle("/get/score-disputes", CORSMiddleware(http.HandlerFunc(HandleGetScoreDisputes)))
+	http.Handle("/post/add-new-score-dispute", CORSMiddleware(http.HandlerFunc(HandleAddNewScoreDispute)))
+	http.Handle("/post/comment-score-dispute", CORSMiddleware(http.HandlerFunc(HandleCommentScoreDispute)))
+	http.Handle("/post/resolve-score-dispute", CORSMiddleware(http.HandlerFunc(HandleResolveScoreDispute)))
 
 	http.Handle("/get/team-members", CORSMiddleware(http.HandlerFunc(HandleGetAllTeamMembers)))
 	http.Handle("/post/update-team-member", CORSMiddleware(http.HandlerFunc(HandleUpdateTeamMember)))
 	http.Handle("/post/add-new-team-member", CORSMiddleware(http.HandlerFunc(HandleAddNewTeamMember)))
 	http.Handle("/post/delete-team-member", CORSMiddleware(http.HandlerFunc(HandleDeleteTeamMember)))
+	http.Handle("/post/restore-team-member", CORSMiddleware(http.HandlerFunc(HandleRestoreTeamMember)))
+	http.Handle("/post/transfer-team-member", CORSMiddleware(http.HandlerFunc(HandleTransferTeamMember)))
+	http.Handle("/post/add-member-membership", CORSMiddleware(http.HandlerFunc(HandleAddMembership)))
+	http.Handle("/post/remove-member-membership", CORSMiddleware(http.HandlerFunc(HandleRemoveMembership)))
+	http.Handle("/get/member-history", CORSMiddleware(http.HandlerFunc(HandleGetMemberHistory)))
 
 	http.Handle("/get/project-details", CORSMiddleware(http.HandlerFunc(HandleGetAllProjectDetails)))
 	http.Handle("/post/add-new-project-detail", CORSMiddleware(http.HandlerFunc(HandleAddNewProjectDetail)))
 	http.Handle("/post/update-project-detail", CORSMiddleware(http.HandlerFunc(HandleUpdateProjectDetail)))
 	http.Handle("/post/delete-project-detail", CORSMiddleware(http.HandlerFunc(HandleDeleteProjectDetail)))
+	http.Handle("/post/restore-project-detail", CORSMiddleware(http.HandlerFunc(HandleRestoreProjectDetail)))
+	http.Handle("/post/add-project-alias", CORSMiddleware(http.HandlerFunc(HandleAddProjectAlias)))
+	http.Handle("/post/remove-project-alias", CORSMiddleware(http.HandlerFunc(HandleRemoveProjectAlias)))
+	http.Handle("/get/unknown-projects", CORSMiddleware(http.HandlerFunc(HandleGetUnknownProjects)))
+	http.Handle("/post/add-project-assignment", CORSMiddleware(http.HandlerFunc(HandleAddProjectAssignment)))
+	http.Handle("/post/end-project-assignment", CORSMiddleware(http.HandlerFunc(HandleEndProjectAssignment)))
+	http.Handle("/post/project-ownership", CORSMiddleware(http.HandlerFunc(HandlePostProjectOwnership)))
 
 	http.Handle("/get/creative-tools", CORSMiddleware(http.HandlerFunc(HandleGetAllCreativeTools)))
 	http.Handle("/post/update-creative-tool", CORSMiddleware(http.HandlerFunc(HandleUpdateCreativeTool)))
 	http.Handle("/post/add-new-creative-tool", CORSMiddleware(http.HandlerFunc(HandleAddNewCreativeTool)))
 	http.Handle("/post/delete-creative-tool", CORSMiddleware(http.HandlerFunc(HandleDeleteCreativeTool)))
+	http.Handle("/post/restore-creative-tool", CORSMiddleware(http.HandlerFunc(HandleRestoreCreativeTool)))
 
 	http.Handle("/get/levels", CORSMiddleware(http.HandlerFunc(HandleGetAllLevel)))
 	http.Handle("/post/update-level", CORSMiddleware(http.HandlerFunc(HandleUpdateLevel)))
 	http.Handle("/post/add-new-level", CORSMiddleware(http.HandlerFunc(HandleAddNewLevel)))
 	http.Handle("/post/delete-level", CORSMiddleware(http.HandlerFunc(HandleDeleteLevel)))
+	http.Handle("/post/restore-level", CORSMiddleware(http.HandlerFunc(HandleRestoreLevel)))
 
 	http.Handle("/get/weekly-target", CORSMiddleware(http.HandlerFunc(HandleGetWeeklyTarget)))
 	http.Handle("/post/update-weekly-target", CORSMiddleware(http.HandlerFunc(HandleUpdateWeeklyTarget)))
 	http.Handle("/post/add-new-weekly-target", CORSMiddleware(http.HandlerFunc(HandleAddNewWeeklyTarget)))
 	http.Handle("/post/delete-weekly-target", CORSMiddleware(http.HandlerFunc(HandleDeleteWeeklyTarget)))
-
+	http.Handle("/post/restore-weekly-target", CORSMiddleware(http.HandlerFunc(HandleRestoreWeeklyTarget)))
+	http.Handle("/get/weekly-target-overrides", CORSMiddleware(http.HandlerFunc(HandleGetWeeklyTargetOverrides)))
+	http.Handle("/post/set-weekly-target-override", CORSMiddleware(http.HandlerFunc(HandleSetWeeklyTargetOverride)))
+	http.Handle("/post/delete-weekly-target-override", CORSMiddleware(http.HandlerFunc(HandleDeleteWeeklyTargetOverride)))
+	http.Handle("/post/effective-weekly-targets", CORSMiddleware(http.HandlerFunc(HandleEffectiveWeeklyTargets)))
+
+	http.Handle("/get/holidays", CORSMiddleware(http.HandlerFunc(HandleGetHolidays)))
+	http.Handle("/post/add-new-holiday", CORSMiddleware(http.HandlerFunc(HandleAddNewHoliday)))
+	http.Handle("/post/update-holiday", CORSMiddleware(http.HandlerFunc(HandleUpdateHoliday)))
+	http.Handle("/post/delete-holiday", CORSMiddleware(http.HandlerFunc(HandleDeleteHoliday)))
+	http.Handle("/get/member-leaves", CORSMiddleware(http.HandlerFunc(HandleGetMemberLeaves)))
+	http.Handle("/post/add-new-member-leave", CORSMiddleware(http.HandlerFunc(HandleAddNewMemberLeave)))
+	http.Handle("/post/update-member-leave", CORSMiddleware(http.HandlerFunc(HandleUpdateMemberLeave)))
+	http.Handle("/post/delete-member-leave", CORSMiddleware(http.HandlerFunc(HandleDeleteMemberLeave)))
+	http.Handle("/post/import-calendar", CORSMiddleware(http.HandlerFunc(HandleImportCalendar)))
+
+	http.Handle("/get/member-targets", CORSMiddleware(http.HandlerFunc(HandleGetMemberTargets)))
+	http.Handle("/post/update-member-target", CORSMiddleware(http.HandlerFunc(HandleUpdateMemberTarget)))
+	http.Handle("/post/add-new-member-target", CORSMiddleware(http.HandlerFunc(HandleAddNewMemberTarget)))
+	http.Handle("/post/delete-member-target", CORSMiddleware(http.HandlerFunc(HandleDeleteMemberTarget)))
+	http.Handle("/post/restore-member-target", CORSMiddleware(http.HandlerFunc(HandleRestoreMemberTarget)))
+	http.Handle("/get/target-weights", CORSMiddleware(http.HandlerFunc(HandleGetTargetWeights)))
+	http.Handle("/post/update-target-weight", CORSMiddleware(http.HandlerFunc(HandleUpdateTargetWeight)))
+
+	http.Handle("/get/asset-types", CORSMiddleware(http.HandlerFunc(HandleGetAllAssetTypes)))
+	http.Handle("/post/add-new-asset-type", CORSMiddleware(http.HandlerFunc(HandleAddNewAssetType)))
+	http.Handle("/post/update-asset-type", CORSMiddleware(http.HandlerFunc(HandleUpdateAssetType)))
+	http.Handle("/post/delete-asset-type", CORSMiddleware(http.HandlerFunc(HandleDeleteAssetType)))
+	http.Handle("/post/restore-asset-type", CORSMiddleware(http.HandlerFunc(HandleRestoreAssetType)))
 	http.Handle("/get/weekly-order", CORSMiddleware(http.HandlerFunc(HandleGetWeeklyOrder)))
 	http.Handle("/post/update-weekly-order", CORSMiddleware(http.HandlerFunc(HandleUpdateWeeklyOrder)))
 	http.Handle("/post/add-new-weekly-order", CORSMiddleware(http.HandlerFunc(HandleAddNewWeeklyOrder)))
 	http.Handle("/post/delete-weekly-order", CORSMiddleware(http.HandlerFunc(HandleDeleteWeeklyOrder)))
+	http.Handle("/post/restore-weekly-order", CORSMiddleware(http.HandlerFunc(HandleRestoreWeeklyOrder)))
 
+	http.Handle("/post/order-reconciliation", CORSMiddleware(http.HandlerFunc(HandlePostOrderReconciliation)))
+	http.Handle("/post/project-performance", CORSMiddleware(http.HandlerFunc(HandlePostProjectPerformance)))
 	http.Handle("/post/project-issues", CORSMiddleware(http.HandlerFunc(HandlePostProjectIssues)))
+
+	http.Handle("/post/import", CORSMiddleware(http.HandlerFunc(HandleImport)))
+	http.Handle("/post/export-performance", CORSMiddleware(http.HandlerFunc(HandleExportPerformance)))
 	go ClearSessionMapSchedule()
 
 }
@@ -0,0 +1,125 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"performance-dashboard-backend/internal/calendar"
+	db "performance-dashboard-backend/internal/database"
+	"time"
+)
+
+// visibleTeams returns the teams the session user may see: every current team for admins, their own teams otherwise
+func visibleTeams(r *http.Request) ([]string, bool, error) {
+	teamRoles, ok := GetUserRole(r.Header.Get("Authorization"))
+	if !ok || teamRoles == nil {
+		return nil, false, nil
+	}
+	teams := []string{}
+	isAdmin := false
+	for _, role := range teamRoles {
+		if role.Role == "admin" {
+			isAdmin = true
+		}
+		if !contains(teams, role.Team) {
+			teams = append(teams, role.Team)
+		}
+	}
+	if !isAdmin {
+		return teams, true, nil
+	}
+
+	allTeams, err := db.GetAllTeams(db.GetDatabaseName(), db.GetCollections().StaffMember, time.Now())
+	if err != nil {
+		return nil, true, err
+	}
+	teams = []string{}
+	for _, t := range allTeams {
+		teams = append(teams, t.ID)
+	}
+	return teams, true, nil
+}
+
+// parseTeamRange reads startDate, endDate and teams from the body, teams defaulting to every visible team.
+// It writes the error response and returns false when the request is invalid or not allowed.
+func parseTeamRange(w http.ResponseWriter, r *http.Request) ([]string, time.Time, time.Time, bool) {
+	var startTime, endTime time.Time
+	allowed, ok, err := visibleTeams(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, startTime, endTime, false
+	}
+	if err != nil {
+		log.Println("Error getting all teams:", err)
+		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		return nil, startTime, endTime, false
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return nil, startTime, endTime, false
+	}
+	startTimeStr, _ := body["startDate"].(string)
+	endTimeStr, _ := body["endDate"].(string)
+	if startTime, err = time.Parse(time.RFC3339, startTimeStr); err != nil {
+		http.Error(w, "Invalid startDate", http.StatusBadRequest)
+		return nil, startTime, endTime, false
+	}
+	if endTime, err = time.Parse(time.RFC3339, endTimeStr); err != nil {
+		http.Error(w, "Invalid endDate", http.StatusBadRequest)
+		return nil, startTime, endTime, false
+	}
+
+	teams := allowed
+	if teamsInterface, _ := body["teams"].([]interface{}); len(teamsInterface) > 0 {
+		teams = []string{}
+		for _, v := range teamsInterface {
+			team, _ := v.(string)
+			if !contains(allowed, team) {
+				http.Error(w, "Forbidden: no access to team "+team, http.StatusForbidden)
+				return nil, startTime, endTime, false
+			}
+			teams = append(teams, team)
+		}
+	}
+	return teams, startTime, endTime, true
+}
+
+// HandleTargetAttainment returns points vs. weekly targets for teams and their members, with streaks and ranks.
+// Query: granularity=week|month|quarter. Body: startDate, endDate, teams (optional, defaults to every visible team).
+func HandleTargetAttainment(w http.ResponseWriter, r *http.Request) {
+	teams, startTime, endTime, ok := parseTeamRange(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := db.GetTargetAttainment(db.GetMongoClient(), db.GetDatabaseName(), teams, startTime, endTime, calendar.Unit(r.URL.Query().Get("granularity")))
+	if err != nil {
+		if errors.Is(err, calendar.ErrInvalidGranularity) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleEffectiveWeeklyTargets returns the target in force for every team and week, with the rule or override it comes from.
+// Body: startDate, endDate, teams (optional, defaults to every visible team).
+func HandleEffectiveWeeklyTargets(w http.ResponseWriter, r *http.Request) {
+	teams, startTime, endTime, ok := parseTeamRange(w, r)
+	if !ok {
+		return
+	}
+	targets, err := db.GetEffectiveWeeklyTargets(db.GetMongoClient(), db.GetDatabaseName(), teams, startTime, endTime)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(targets)
+}
@@ -0,0 +1,319 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"performance-dashboard-backend/internal/calendar"
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/importer"
+)
+
+/// ======================================================
+/// ============= Holiday Handler ========================
+
+// parseHoliday reads Name, DateFrom and DateTo, and ID when updating. DateTo defaults to DateFrom.
+func parseHoliday(body map[string]interface{}, withID bool) (*collectionmodels.Holiday, error) {
+	name, _ := body["Name"].(string)
+	dateFrom := parseOptionalTime(body, "DateFrom")
+	if name == "" || dateFrom == nil {
+		return nil, errors.New("Name and DateFrom are required")
+	}
+	holiday := &collectionmodels.Holiday{Name: name, DateFrom: *dateFrom, DateTo: *dateFrom}
+	if dateTo := parseOptionalTime(body, "DateTo"); dateTo != nil {
+		holiday.DateTo = *dateTo
+	}
+	if withID {
+		id, err := parseObjectID(body, "ID")
+		if err != nil {
+			return nil, err
+		}
+		holiday.ID = id
+	}
+	return holiday, nil
+}
+
+func HandleGetHolidays(w http.ResponseWriter, r *http.Request) {
+	spec, err := parseQuerySpec(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	holidays, nextCursor, err := collectionmodels.GetHolidaysPage(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().Holiday, spec)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, holidays, nextCursor)
+}
+
+func HandleAddNewHoliday(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	holiday, err := parseHoliday(body, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = checkTeamWeeksOpen(nil, holiday.DateFrom, &holiday.DateTo)
+	if err == nil {
+		err = collectionmodels.InsertHoliday(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().Holiday, holiday)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "New holiday added successfully"}`))
+}
+
+func HandleUpdateHoliday(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	holiday, err := parseHoliday(body, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = storedHolidayOpen(holiday.ID)
+	if err == nil {
+		err = checkTeamWeeksOpen(nil, holiday.DateFrom, &holiday.DateTo)
+	}
+	if err == nil {
+		err = collectionmodels.UpdateHoliday(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().Holiday, holiday)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Holiday updated successfully"}`))
+}
+
+func HandleDeleteHoliday(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	id, err := parseObjectID(body, "ID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	err = storedHolidayOpen(id)
+	if err == nil {
+		err = collectionmodels.DeleteHoliday(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().Holiday, id, deletedBy)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Holiday deleted successfully"}`))
+}
+
+/// ======================================================
+/// ============= Member Leave Handler ===================
+
+// parseMemberLeave reads Email, Reason, DateFrom and DateTo, and ID when updating. DateTo defaults to DateFrom.
+func parseMemberLeave(body map[string]interface{}, withID bool) (*collectionmodels.MemberLeave, error) {
+	email, _ := body["Email"].(string)
+	dateFrom := parseOptionalTime(body, "DateFrom")
+	if email == "" || dateFrom == nil {
+		return nil, errors.New("Email and DateFrom are required")
+	}
+	reason, _ := body["Reason"].(string)
+	leave := &collectionmodels.MemberLeave{Email: email, Reason: reason, DateFrom: *dateFrom, DateTo: *dateFrom}
+	if dateTo := parseOptionalTime(body, "DateTo"); dateTo != nil {
+		leave.DateTo = *dateTo
+	}
+	if withID {
+		id, err := parseObjectID(body, "ID")
+		if err != nil {
+			return nil, err
+		}
+		leave.ID = id
+	}
+	return leave, nil
+}
+
+func HandleGetMemberLeaves(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	spec, err := parseQuerySpec(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	leaves, nextCursor, err := collectionmodels.GetMemberLeavesPage(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().MemberLeave, spec)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, leaves, nextCursor)
+}
+
+func HandleAddNewMemberLeave(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	leave, err := parseMemberLeave(body, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = checkMemberWeeksOpen(leave.Email, leave.DateFrom, &leave.DateTo)
+	if err == nil {
+		err = collectionmodels.InsertMemberLeave(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().MemberLeave, leave)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "New leave added successfully"}`))
+}
+
+func HandleUpdateMemberLeave(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	leave, err := parseMemberLeave(body, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = storedMemberLeaveOpen(leave.ID)
+	if err == nil {
+		err = checkMemberWeeksOpen(leave.Email, leave.DateFrom, &leave.DateTo)
+	}
+	if err == nil {
+		err = collectionmodels.UpdateMemberLeave(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().MemberLeave, leave)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Leave updated successfully"}`))
+}
+
+func HandleDeleteMemberLeave(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	id, err := parseObjectID(body, "ID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	deletedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	err = storedMemberLeaveOpen(id)
+	if err == nil {
+		err = collectionmodels.DeleteMemberLeave(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().MemberLeave, id, deletedBy)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Leave deleted successfully"}`))
+}
+
+// HandleImportCalendar imports the events of an ICS file as company holidays or as leaves of one member.
+// Query: type=holidays|leave, email (for leave). Form: file. Events already imported are updated by their UID,
+// events touching a closed week are skipped. Recurring events are not imported, they are listed in Errors.
+func HandleImportCalendar(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	kind := r.URL.Query().Get("type")
+	email := r.URL.Query().Get("email")
+	if kind != "holidays" && kind != "leave" {
+		http.Error(w, "Invalid type, expected holidays or leave", http.StatusBadRequest)
+		return
+	}
+	if kind == "leave" && email == "" {
+		http.Error(w, "Missing email", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Missing file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	events, rejected, err := importer.ReadEvents(file, calendar.Location())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	client := db.GetMongoClient()
+	dbName := db.GetDatabaseName()
+	inserted, updated, skipped := 0, 0, 0
+	for _, e := range events {
+		// Events of closed weeks are left as they are
+		if kind == "holidays" {
+			err = checkTeamWeeksOpen(nil, e.DateFrom, &e.DateTo)
+		} else {
+			err = checkMemberWeeksOpen(email, e.DateFrom, &e.DateTo)
+		}
+		if errors.Is(err, collectionmodels.ErrWeekClosed) {
+			skipped++
+			continue
+		}
+		if err != nil {
+			writeDatabaseError(w, err)
+			return
+		}
+		var isNew bool
+		if kind == "holidays" {
+			isNew, err = collectionmodels.UpsertHolidayByUID(client, dbName, db.GetCollections().Holiday, &collectionmodels.Holiday{
+				Name: e.Summary, DateFrom: e.DateFrom, DateTo: e.DateTo, UID: e.UID,
+			})
+		} else {
+			isNew, err = collectionmodels.UpsertMemberLeaveByUID(client, dbName, db.GetCollections().MemberLeave, &collectionmodels.MemberLeave{
+				Email: email, Reason: e.Summary, DateFrom: e.DateFrom, DateTo: e.DateTo, UID: e.UID,
+			})
+		}
+		if err != nil {
+			writeDatabaseError(w, err)
+			return
+		}
+		if isNew {
+			inserted++
+		} else {
+			updated++
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"Events":   len(events) + len(rejected),
+		"Inserted": inserted,
+		"Updated":  updated,
+		"Skipped":  skipped,
+		"Errors":   rejected,
+	})
+}
@@ -0,0 +1,192 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"performance-dashboard-backend/internal/calendar"
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/// ======================================================
+/// ============= Week Closure Handler ===================
+
+// canManageTeam reports whether the session user is an admin or a manager of team, ok is false without a valid session
+func canManageTeam(r *http.Request, team string) (allowed bool, ok bool) {
+	teamRoles, ok := GetUserRole(r.Header.Get("Authorization"))
+	if !ok || teamRoles == nil {
+		return false, false
+	}
+	for _, role := range teamRoles {
+		if role.Role == "admin" || (role.Role == "manager" && role.Team == team) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+func HandleGetWeekClosures(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	spec, err := parseQuerySpec(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	closures, nextCursor, err := collectionmodels.GetWeekClosuresPage(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeekClosure, spec)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, closures, nextCursor)
+}
+
+// HandleCloseWeek locks the scores of a team for the week containing WeekStart.
+// Admins and managers of the team only. Body: Team, WeekStart
+func HandleCloseWeek(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	team, _ := body["Team"].(string)
+	weekStart := parseOptionalTime(body, "WeekStart")
+	if team == "" || weekStart == nil {
+		http.Error(w, "Team and WeekStart are required", http.StatusBadRequest)
+		return
+	}
+	allowed, ok := canManageTeam(r, team)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !allowed {
+		http.Error(w, "Forbidden: no access to team "+team, http.StatusForbidden)
+		return
+	}
+
+	closedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	closure, err := db.CloseTeamWeek(db.GetMongoClient(), db.GetDatabaseName(), team, *weekStart, closedBy)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(closure)
+}
+
+// HandleReopenWeek unlocks a closed week, its scores follow the tasks again.
+// Admins and managers of the team only. Body: ID
+func HandleReopenWeek(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	id, err := parseObjectID(body, "ID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	closure, err := collectionmodels.GetWeekClosure(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeekClosure, id)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	allowed, ok := canManageTeam(r, closure.Team)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !allowed {
+		http.Error(w, "Forbidden: no access to team "+closure.Team, http.StatusForbidden)
+		return
+	}
+
+	reopenedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	if err := db.ReopenTeamWeek(db.GetMongoClient(), db.GetDatabaseName(), id, reopenedBy); err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Week reopened successfully"}`))
+}
+
+// checkTeamWeeksOpen returns ErrWeekClosed when [from, to] touches a closed week of one of the teams, nil teams meaning any
+func checkTeamWeeksOpen(teams []string, from time.Time, to *time.Time) error {
+	return collectionmodels.CheckTeamWeeksOpen(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeekClosure, teams, from, to)
+}
+
+// checkMemberWeeksOpen returns ErrWeekClosed when [from, to] touches a week closed for a team of the member
+func checkMemberWeeksOpen(email string, from time.Time, to *time.Time) error {
+	return collectionmodels.CheckMemberWeeksOpen(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeekClosure, email, from, to)
+}
+
+// The stored* checks cover the weeks of a record before it is changed, deleted or restored
+
+func storedWeeklyTargetOpen(id primitive.ObjectID) error {
+	target, err := collectionmodels.FindByID[collectionmodels.WeeklyTarget](db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyTarget, id)
+	if err != nil {
+		return err
+	}
+	return checkTeamWeeksOpen([]string{target.Team}, target.DateFrom, target.DateTo)
+}
+
+// weeklyTargetChangeOpen checks the weeks an update of a rule affects. Only ending or extending a rule
+// leaves the weeks before both ends untouched, so a rule in force over closed weeks can still be ended.
+func weeklyTargetChangeOpen(target *collectionmodels.WeeklyTarget) error {
+	stored, err := collectionmodels.FindByID[collectionmodels.WeeklyTarget](db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyTarget, target.ID)
+	if err != nil {
+		return err
+	}
+	sameRule := stored.Team == target.Team && stored.Point == target.Point && stored.EveryWeeks == target.EveryWeeks && stored.DateFrom.Equal(target.DateFrom)
+	if !sameRule {
+		if err := checkTeamWeeksOpen([]string{stored.Team}, stored.DateFrom, stored.DateTo); err != nil {
+			return err
+		}
+		return checkTeamWeeksOpen([]string{target.Team}, target.DateFrom, target.DateTo)
+	}
+	// Weeks starting after the earlier end change, a nil end is the later one
+	from := stored.DateTo
+	if from == nil || (target.DateTo != nil && target.DateTo.Before(*from)) {
+		from = target.DateTo
+	}
+	if from == nil {
+		return nil
+	}
+	return checkTeamWeeksOpen([]string{target.Team}, calendar.StartOfWeek(*from).AddDate(0, 0, 7), nil)
+}
+
+func storedWeeklyTargetOverrideOpen(id primitive.ObjectID) error {
+	override, err := collectionmodels.FindByID[collectionmodels.WeeklyTargetOverride](db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().WeeklyTargetOverride, id)
+	if err != nil {
+		return err
+	}
+	return checkTeamWeeksOpen([]string{override.Team}, override.WeekStart, &override.WeekStart)
+}
+
+func storedMemberTargetOpen(id primitive.ObjectID) error {
+	target, err := collectionmodels.FindByID[collectionmodels.MemberTarget](db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().MemberTarget, id)
+	if err != nil {
+		return err
+	}
+	return checkMemberWeeksOpen(target.Email, target.DateFrom, &target.DateTo)
+}
+
+func storedHolidayOpen(id primitive.ObjectID) error {
+	holiday, err := collectionmodels.FindByID[collectionmodels.Holiday](db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().Holiday, id)
+	if err != nil {
+		return err
+	}
+	return checkTeamWeeksOpen(nil, holiday.DateFrom, &holiday.DateTo)
+}
+
+func storedMemberLeaveOpen(id primitive.ObjectID) error {
+	leave, err := collectionmodels.FindByID[collectionmodels.MemberLeave](db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().MemberLeave, id)
+	if err != nil {
+		return err
+	}
+	return checkMemberWeeksOpen(leave.Email, leave.DateFrom, &leave.DateTo)
+}
@@ -0,0 +1,233 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"performance-dashboard-backend/internal/asana"
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/notify"
+	"time"
+)
+
+/// ======================================================
+/// ============= Score Dispute Handler ==================
+
+// HandleGetScoreDisputes lists disputes. Query: the usual list parameters, status=open|accepted|rejected
+func HandleGetScoreDisputes(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	spec, err := parseQuerySpec(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	disputes, nextCursor, err := collectionmodels.GetScoreDisputesPage(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections().ScoreDispute, spec, r.URL.Query().Get("status"))
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, disputes, nextCursor)
+}
+
+// HandleAddNewScoreDispute contests the scoring of a completed task. The assignee of the task and managers
+// of its team only. Body: TaskID, ProposedLevel, ProposedTools, Reason
+func HandleAddNewScoreDispute(w http.ResponseWriter, r *http.Request) {
+	email, ok := GetEmailFromToken(r.Header.Get("Authorization"))
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	taskID, err := parseObjectID(body, "TaskID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	level, ok := body["ProposedLevel"].(float64)
+	reason, _ := body["Reason"].(string)
+	if !ok || reason == "" {
+		http.Error(w, "ProposedLevel and Reason are required", http.StatusBadRequest)
+		return
+	}
+	toolsInterface, _ := body["ProposedTools"].([]interface{})
+	tools := make([]int, 0, len(toolsInterface))
+	for _, v := range toolsInterface {
+		idx, ok := v.(float64)
+		if !ok {
+			http.Error(w, "Invalid ProposedTools", http.StatusBadRequest)
+			return
+		}
+		tools = append(tools, int(idx))
+	}
+
+	client := db.GetMongoClient()
+	dbName := db.GetDatabaseName()
+	task, err := collectionmodels.FindByID[collectionmodels.CompletedTask](client, dbName, db.GetCollections().CompletedTask, taskID)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	if task.AssigneeID != email {
+		if allowed, _ := canManageTeam(r, task.Team); !allowed {
+			http.Error(w, "Forbidden: not the assignee of the task", http.StatusForbidden)
+			return
+		}
+	}
+
+	// Closed weeks can no longer be rescored, a dispute there could never be accepted
+	err = checkTeamWeeksOpen([]string{task.Team}, task.DoneDate, &task.DoneDate)
+	if err == nil {
+		err = checkMemberWeeksOpen(task.AssigneeID, task.DoneDate, &task.DoneDate)
+	}
+	if err == nil {
+		err = db.ValidateTaskScoring(client, dbName, task.Team, int(level), tools)
+	}
+	dispute := &collectionmodels.ScoreDispute{
+		TaskID:        task.ID,
+		AsanaTaskID:   task.TaskID,
+		TaskName:      task.TaskName,
+		Email:         task.AssigneeID,
+		Team:          task.Team,
+		DoneDate:      task.DoneDate,
+		Level:         task.Level,
+		Tools:         task.Tool,
+		ProposedLevel: int(level),
+		ProposedTools: tools,
+		Reason:        reason,
+		CreatedBy:     email,
+		CreatedAt:     time.Now(),
+	}
+	if err == nil {
+		err = collectionmodels.InsertScoreDispute(client, dbName, db.GetCollections().ScoreDispute, dispute)
+	}
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	notify.Send(fmt.Sprintf("%s disputes the scoring of %q (%s team): level %d, tools %v proposed instead of level %d, tools %v. Reason: %s",
+		email, task.TaskName, task.Team, dispute.ProposedLevel, dispute.ProposedTools, task.Level, task.Tool, reason))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dispute)
+}
+
+// HandleCommentScoreDispute adds a comment to an open dispute. The assignee of the task, the author of the
+// dispute and managers of the team only. Body: ID, Body
+func HandleCommentScoreDispute(w http.ResponseWriter, r *http.Request) {
+	email, ok := GetEmailFromToken(r.Header.Get("Authorization"))
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	id, err := parseObjectID(body, "ID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	text, _ := body["Body"].(string)
+	if text == "" {
+		http.Error(w, "Body is required", http.StatusBadRequest)
+		return
+	}
+
+	client := db.GetMongoClient()
+	dbName := db.GetDatabaseName()
+	disputeColl := db.GetCollections().ScoreDispute
+	dispute, err := collectionmodels.FindByID[collectionmodels.ScoreDispute](client, dbName, disputeColl, id)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	if email != dispute.Email && email != dispute.CreatedBy {
+		if allowed, _ := canManageTeam(r, dispute.Team); !allowed {
+			http.Error(w, "Forbidden: no access to team "+dispute.Team, http.StatusForbidden)
+			return
+		}
+	}
+	err = collectionmodels.AddDisputeComment(client, dbName, disputeColl, id, collectionmodels.DisputeComment{Author: email, Body: text, CreatedAt: time.Now()})
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	notify.Send(fmt.Sprintf("%s commented on the dispute of %q (%s team): %s", email, dispute.TaskName, dispute.Team, text))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Comment added successfully"}`))
+}
+
+// HandleResolveScoreDispute accepts or rejects an open dispute, accepting rescores the task.
+// With CommentOnAsana the resolution is posted as a comment on the Asana task, its level and tool fields in Asana
+// are left as they are. Admins and managers of the team only. Body: ID, Accept, Resolution, CommentOnAsana
+func HandleResolveScoreDispute(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	id, err := parseObjectID(body, "ID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	accept, ok := body["Accept"].(bool)
+	if !ok {
+		http.Error(w, "Accept is required", http.StatusBadRequest)
+		return
+	}
+	resolution, _ := body["Resolution"].(string)
+	commentOnAsana, _ := body["CommentOnAsana"].(bool)
+
+	client := db.GetMongoClient()
+	dbName := db.GetDatabaseName()
+	dispute, err := collectionmodels.FindByID[collectionmodels.ScoreDispute](client, dbName, db.GetCollections().ScoreDispute, id)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	allowed, ok := canManageTeam(r, dispute.Team)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !allowed {
+		http.Error(w, "Forbidden: no access to team "+dispute.Team, http.StatusForbidden)
+		return
+	}
+	resolvedBy, _ := GetEmailFromToken(r.Header.Get("Authorization"))
+	dispute, err = db.ResolveScoreDispute(client, dbName, id, accept, resolvedBy, resolution)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+
+	summary := fmt.Sprintf("Score dispute %s by %s: ", dispute.Status, resolvedBy)
+	if accept {
+		summary += fmt.Sprintf("level %d, tools %v.", dispute.ProposedLevel, dispute.ProposedTools)
+	} else {
+		summary += fmt.Sprintf("level %d, tools %v kept.", dispute.Level, dispute.Tools)
+	}
+	if resolution != "" {
+		summary += " " + resolution
+	}
+	notify.Send(fmt.Sprintf("%s (%q, %s team, raised by %s)", summary, dispute.TaskName, dispute.Team, dispute.CreatedBy))
+
+	message := "Score dispute resolved successfully"
+	if commentOnAsana {
+		// The dispute stays resolved when Asana is unreachable
+		if err := asana.AddTaskComment(settings.Asana.Token, dispute.AsanaTaskID, summary); err != nil {
+			log.Println("Error commenting the dispute resolution on Asana:", err)
+			message = "Score dispute resolved, commenting on Asana failed"
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": message, "Dispute": dispute})
+}
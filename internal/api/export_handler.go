@@ -0,0 +1,135 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"performance-dashboard-backend/internal/calendar"
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/report"
+	"time"
+)
+
+// HandleExportPerformance exports performance points as a file.
+// Query: format=csv|xlsx|pdf, kind=member|team|project as for /post/performance-point.
+// Body: same as /post/performance-point. CSV and XLSX have one row per identifier per week,
+// PDF has one page per team with its weekly totals and the points each member earned for it.
+func HandleExportPerformance(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	kind, err := parseIdentifierKind(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format == "pdf" {
+		// The PDF is a team summary
+		kind = collectionmodels.KindTeam
+	}
+
+	startTimeStr, _ := body["startDate"].(string)
+	endTimeStr, _ := body["endDate"].(string)
+	startTime, err := time.Parse(time.RFC3339, startTimeStr)
+	if err != nil {
+		http.Error(w, "Invalid startDate", http.StatusBadRequest)
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, endTimeStr)
+	if err != nil {
+		http.Error(w, "Invalid endDate", http.StatusBadRequest)
+		return
+	}
+	identifiersInterface, _ := body["identifiers"].([]interface{})
+	identifiers := make([]string, 0, len(identifiersInterface))
+	for _, v := range identifiersInterface {
+		if s, ok := v.(string); ok {
+			identifiers = append(identifiers, s)
+		}
+	}
+
+	weeklyRows := func(ids []string, kind collectionmodels.IdentifierKind) ([]report.PerformanceRow, error) {
+		res, err := performancePoints(ids, startTime, endTime, kind, calendar.Weekly)
+		if err != nil {
+			return nil, err
+		}
+		return report.RowsFromPerformance(res), nil
+	}
+
+	fileName := fmt.Sprintf("performance_%s_%s", startTime.Format("20060102"), endTime.Format("20060102"))
+
+	switch format {
+	case "csv", "xlsx":
+		rows, err := weeklyRows(identifiers, kind)
+		if err != nil {
+			writeDatabaseError(w, err)
+			return
+		}
+		if format == "csv" {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", "attachment; filename=\""+fileName+".csv\"")
+			if err := report.WriteCSV(w, rows); err != nil {
+				log.Println("Error writing performance CSV:", err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+fileName+".xlsx\"")
+		if err := report.WriteXLSX(w, rows); err != nil {
+			log.Println("Error writing performance XLSX:", err)
+		}
+
+	case "pdf":
+		var summaries []report.TeamSummary
+		for _, team := range identifiers {
+			weeks, err := weeklyRows([]string{team}, kind)
+			if err != nil {
+				http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			// Former members are named too, they may have points in the period
+			members, err := db.GetMembersByTeam(db.GetDatabaseName(), db.GetCollections().StaffMember, team, true)
+			if err != nil {
+				http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			names := make(map[string]string, len(members))
+			for _, member := range members {
+				names[member.Email] = member.Name
+			}
+			// The points each member earned for this team, so that they add up to the weekly totals
+			points, err := db.GetTeamMemberPoints(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections(), team, startTime, endTime)
+			if err != nil {
+				http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			summary := report.TeamSummary{Team: team, StartDate: startTime, EndDate: endTime, Weeks: weeks}
+			for _, p := range points {
+				if p.TotalPerformancePoint == 0 {
+					continue
+				}
+				row := report.RowsFromPerformance([]db.PerformancePointTotalWithTime{{StartDate: startTime, EndDate: endTime, TotalPerformancePoint: p}})[0]
+				if name := names[p.Identifier]; name != "" {
+					row.Identifier = name
+				}
+				summary.Members = append(summary.Members, row)
+			}
+			summaries = append(summaries, summary)
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+fileName+".pdf\"")
+		if err := report.WriteTeamPDF(w, summaries); err != nil {
+			log.Println("Error writing performance PDF:", err)
+		}
+
+	default:
+		http.Error(w, "Invalid format, expected csv, xlsx or pdf", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,53 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	db "performance-dashboard-backend/internal/database"
+	"performance-dashboard-backend/internal/importer"
+)
+
+const maxImportSize = 10 << 20
+
+// HandleImport bulk inserts or updates records from a CSV or XLSX upload.
+// Query: type=members|weekly-orders|weekly-targets|project-details, dryRun=true to only validate.
+// Form: file. Any invalid row rejects the whole file with 422 and a per row report.
+func HandleImport(w http.ResponseWriter, r *http.Request) {
+	// TODO : implement role-based access control
+	kind := importer.Kind(r.URL.Query().Get("type"))
+	if _, err := kind.Collection(db.GetCollections()); err != nil {
+		http.Error(w, importer.ErrUnknownKind.Error(), http.StatusBadRequest)
+		return
+	}
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Missing file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	rows, err := importer.ReadRows(header.Filename, file)
+	if err != nil {
+		if errors.Is(err, importer.ErrUnsupportedFormat) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Invalid file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := importer.Run(db.GetMongoClient(), db.GetDatabaseName(), db.GetCollections(), kind, rows, dryRun)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if len(report.Errors) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,62 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"net/http"
+	db "performance-dashboard-backend/internal/database"
+	"time"
+)
+
+// isAdminRequest reports whether the session user holds the admin role, ok is false without a valid session
+func isAdminRequest(r *http.Request) (isAdmin bool, ok bool) {
+	teamRoles, ok := GetUserRole(r.Header.Get("Authorization"))
+	if !ok || teamRoles == nil {
+		return false, false
+	}
+	for _, role := range teamRoles {
+		if role.Role == "admin" {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// HandleRebuildPerformanceSnapshots recomputes the weekly performance snapshots of every member and team.
+// Admin only. Body: startDate, endDate, every week touching the range is rebuilt.
+func HandleRebuildPerformanceSnapshots(w http.ResponseWriter, r *http.Request) {
+	isAdmin, ok := isAdminRequest(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !isAdmin {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	startTimeStr, _ := body["startDate"].(string)
+	endTimeStr, _ := body["endDate"].(string)
+	startTime, err := time.Parse(time.RFC3339, startTimeStr)
+	if err != nil {
+		http.Error(w, "Invalid startDate", http.StatusBadRequest)
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, endTimeStr)
+	if err != nil || endTime.Before(startTime) {
+		http.Error(w, "Invalid endDate", http.StatusBadRequest)
+		return
+	}
+
+	res, err := db.RebuildPerformanceSnapshots(db.GetMongoClient(), db.GetDatabaseName(), startTime, endTime)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -1,15 +1,21 @@
 package asana
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
-	"os"
+	"performance-dashboard-backend/internal/calendar"
+	"performance-dashboard-backend/internal/config"
 	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/notify"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -68,22 +74,24 @@ func InsertCompletedTaskToDataBase(client *mongo.Client, dbName, collName string
 	return err
 }
 
+var settings config.Asana
+
+// Configure sets the token and the projects synced by SyncronizeWeeklyTasks
+func Configure(cfg config.Asana) {
+	settings = cfg
+}
+
 func FetchAsanaTasksTeamPlayable(team string, projectID string) []*collectionmodels.CompletedTask {
-	token := os.Getenv("ASANA_TOKEN") // safer to set as env var
-	tasks, err := FetchTasks(token, projectID)
+	tasks, err := FetchTasks(settings.Token, projectID)
 	if err != nil {
 		fmt.Println("Error:", err)
 		return nil
 	}
 
 	var completedTasks []*collectionmodels.CompletedTask
-	var thisMondayAtNine time.Time
-	now := time.Now()
-	weekday := int(now.Weekday())
-	if weekday == 0 {
-		weekday = 7
-	}
-	thisMondayAtNine = now.AddDate(0, 0, -weekday+1).Truncate(24 * time.Hour).Add(9 * time.Hour)
+	// Tasks are dated 9:00 on the last day of the latest closed week
+	_, weekEnd := calendar.LastClosedWeek(time.Now())
+	doneDate := calendar.StartOfDay(weekEnd).Add(9 * time.Hour)
 	for _, task := range tasks {
 
 		if !task.Completed {
@@ -118,7 +126,7 @@ func FetchAsanaTasksTeamPlayable(team string, projectID string) []*collectionmod
 
 		completedTask := &collectionmodels.CompletedTask{
 			TaskID:     task.Gid,
-			DoneDate:   thisMondayAtNine,
+			DoneDate:   doneDate,
 			TaskName:   task.Name,
 			AssigneeID: task.Assignee.Email,
 			Team:       team,
@@ -152,30 +160,97 @@ func GetListToolAsIndexes(s string) []int {
 	return numbers
 }
 
-// schedule to run every Monday at 11:59 AM
-// Implementation of scheduling logic goes here
+// schedule to run at 11:59 AM on the last day of every week (Monday by default), in the calendar time zone
 func ScheduleWeeklyTaskSync() {
-	c := cron.New()
-	c.AddFunc("59 11 * * 1", SyncronizeWeeklyTasks)
+	c := cron.New(cron.WithLocation(calendar.Location()))
+	lastDay := (calendar.WeekStart() + 6) % 7
+	c.AddFunc(fmt.Sprintf("59 11 * * %d", lastDay), SyncronizeWeeklyTasks)
 	c.Start()
 }
 
+// resolveProjects links tasks to the catalog project their game name goes by and returns the names matching none
+func resolveProjects(catalog *collectionmodels.ProjectCatalog, tasks []*collectionmodels.CompletedTask) []string {
+	var unknown []string
+	for _, task := range tasks {
+		if task.Project == "" {
+			continue
+		}
+		if id, ok := catalog.Resolve(task.Project); ok {
+			task.ProjectID = id
+		} else if !slices.Contains(unknown, task.Project) {
+			unknown = append(unknown, task.Project)
+		}
+	}
+	return unknown
+}
+
 func SyncronizeWeeklyTasks() {
-	plaCompltedTasks := FetchAsanaTasksTeamPlayable("PLA", os.Getenv("ASANA_PROJECT_ID_PLA"))
-	if len(plaCompltedTasks) > 0 {
+	dbName := db.GetDatabaseName()
+	taskColl := db.GetCollections().CompletedTask
+	catalog, err := collectionmodels.LoadProjectCatalog(db.GetMongoClient(), dbName, db.GetCollections().ProjectDetail)
+	if err != nil {
+		log.Printf("Asana sync: loading the project catalog from %s failed, no project synced: %v", db.GetCollections().ProjectDetail, err)
+		return
+	}
+	var unknown []string
+	// Teams without a configured project are not synced
+	for _, project := range []struct{ team, id string }{
+		{"PLA", settings.ProjectPLA},
+		{"Video", settings.ProjectVideo},
+		{"Art", settings.ProjectArt},
+		{"Concept", settings.ProjectConcept},
+	} {
+		if project.id == "" {
+			continue
+		}
+		completedTasks := FetchAsanaTasksTeamPlayable(project.team, project.id)
+		for _, name := range resolveProjects(catalog, completedTasks) {
+			if !slices.Contains(unknown, name) {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(completedTasks) > 0 {
+			if err := collectionmodels.InsertCompletedTaskToDataBase(db.GetMongoClient(), dbName, taskColl, completedTasks); err != nil {
+				log.Printf("Asana sync: inserting the completed tasks of project %s (%s) failed: %v", project.id, project.team, err)
+			}
+		}
+	}
+	// Tasks of unknown projects are kept unlinked until the project or an alias is added to the catalog
+	if len(unknown) > 0 {
+		notify.Send(fmt.Sprintf("Asana sync: unknown projects %s, add them to the project catalog or as aliases", strings.Join(unknown, ", ")))
+	}
+
+	// The synced week has new tasks, recompute its performance snapshots.
+	// Those of teams that already closed the week stay locked.
+	weekStart, weekEnd := calendar.LastClosedWeek(time.Now())
+	if _, err := db.RebuildPerformanceSnapshots(db.GetMongoClient(), dbName, weekStart, weekEnd); err != nil {
+		log.Printf("Asana sync: rebuilding the performance snapshots of the week of %s failed: %v", weekStart.Format("2006-01-02"), err)
+	}
+}
 
-		collectionmodels.InsertCompletedTaskToDataBase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), plaCompltedTasks)
+// AddTaskComment posts text as a comment on an Asana task
+func AddTaskComment(token string, taskID string, text string) error {
+	data, err := json.Marshal(map[string]interface{}{"data": map[string]string{"text": text}})
+	if err != nil {
+		return err
 	}
-	videoCompletedTasks := FetchAsanaTasksTeamPlayable("Video", os.Getenv("ASANA_PROJECT_ID_VIDEO"))
-	if len(videoCompletedTasks) > 0 {
-		collectionmodels.InsertCompletedTaskToDataBase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), videoCompletedTasks)
+	url := fmt.Sprintf("https://app.asana.com/api/1.0/tasks/%s/stories", taskID)
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
 	}
-	artCompletedTasks := FetchAsanaTasksTeamPlayable("Art", os.Getenv("ASANA_PROJECT_ID_ART"))
-	if len(artCompletedTasks) > 0 {
-		collectionmodels.InsertCompletedTaskToDataBase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), artCompletedTasks)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
 	}
-	conceptCompletedTasks := FetchAsanaTasksTeamPlayable("Concept", os.Getenv("ASANA_PROJECT_ID_CONCEPT"))
-	if len(conceptCompletedTasks) > 0 {
-		collectionmodels.InsertCompletedTaskToDataBase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), conceptCompletedTasks)
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("asana returned %s: %s", resp.Status, body)
 	}
+	return nil
 }
@@ -0,0 +1,118 @@
+// Package calendar holds the day and week rules of the performance calendar.
+// Every date computation (sync, handlers, aggregations) goes through it, so that days
+// are cut in the configured time zone and weeks start on the configured weekday.
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimezone  = "Asia/Ho_Chi_Minh"
+	DefaultWeekStart = time.Tuesday
+)
+
+var (
+	location  = mustLoadLocation(DefaultTimezone)
+	weekStart = DefaultWeekStart
+)
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		// The zone database is missing, Vietnam has no DST so a fixed offset is exact
+		return time.FixedZone(name, 7*60*60)
+	}
+	return loc
+}
+
+// Configure sets the time zone (IANA name) and the first day of the week (e.g. "tuesday").
+// Empty values keep the defaults. It must be called at startup, before any request is served.
+func Configure(timezone, weekStartDay string) error {
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return fmt.Errorf("invalid calendar time zone %q: %w", timezone, err)
+		}
+		location = loc
+	}
+	if weekStartDay != "" {
+		day, err := ParseWeekday(weekStartDay)
+		if err != nil {
+			return err
+		}
+		weekStart = day
+	}
+	return nil
+}
+
+// ParseWeekday reads an English weekday name, case insensitive
+func ParseWeekday(name string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), name) {
+			return d, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("invalid weekday %q", name)
+}
+
+func Location() *time.Location {
+	return location
+}
+
+func WeekStart() time.Weekday {
+	return weekStart
+}
+
+// MongoStartOfWeek is the startOfWeek value of $dateTrunc for the configured week
+func MongoStartOfWeek() string {
+	return strings.ToLower(weekStart.String())
+}
+
+// MongoTimezone is the timezone value of $dateTrunc and $dateToString
+func MongoTimezone() string {
+	return location.String()
+}
+
+// StartOfDay returns 0:00 of the day of t in the calendar time zone
+func StartOfDay(t time.Time) time.Time {
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
+
+// EndOfDay returns the last instant of the day of t in the calendar time zone
+func EndOfDay(t time.Time) time.Time {
+	return StartOfDay(t).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// StartOfWeek returns 0:00 of the first day of the week containing t
+func StartOfWeek(t time.Time) time.Time {
+	day := StartOfDay(t)
+	offset := (int(day.Weekday()) - int(weekStart) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// EndOfWeek returns the last instant of the week containing t
+func EndOfWeek(t time.Time) time.Time {
+	return StartOfWeek(t).AddDate(0, 0, 7).Add(-time.Nanosecond)
+}
+
+// WeeksBetween returns the number of whole weeks from the week of a to the week of b
+func WeeksBetween(a, b time.Time) int {
+	return daysBetween(StartOfWeek(a), StartOfWeek(b)) / 7
+}
+
+// LastClosedWeek returns the latest week that has ended, or ends today: on the last day of a week
+// it is the current week, otherwise the previous one
+func LastClosedWeek(now time.Time) (time.Time, time.Time) {
+	lastDay := StartOfWeek(StartOfDay(now).AddDate(0, 0, 1)).AddDate(0, 0, -1)
+	return StartOfWeek(lastDay), EndOfDay(lastDay)
+}
+
+// SplitWeeks cuts the days from start to end, both included, at week boundaries.
+// The first and last ranges may be partial weeks.
+func SplitWeeks(start, end time.Time) [][2]time.Time {
+	return Weekly.Buckets(start, end)
+}
@@ -0,0 +1,172 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+const layout = "2006-01-02 15:04:05.999999999"
+
+// useCalendar configures the calendar for one test and restores the defaults afterwards
+func useCalendar(t *testing.T, timezone, weekStartDay string) {
+	t.Helper()
+	t.Cleanup(func() {
+		location = mustLoadLocation(DefaultTimezone)
+		weekStart = DefaultWeekStart
+	})
+	if err := Configure(timezone, weekStartDay); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// at parses a date in the calendar time zone
+func at(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation(layout, value, location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestStartAndEndOfWeek(t *testing.T) {
+	tests := []struct {
+		name      string
+		timezone  string
+		weekStart string
+		input     string
+		start     string
+		end       string
+	}{
+		{"tuesday default mid week", "", "", "2024-05-16 10:00:00", "2024-05-14 00:00:00", "2024-05-20 23:59:59.999999999"},
+		{"tuesday default first day", "", "", "2024-05-14 00:00:00", "2024-05-14 00:00:00", "2024-05-20 23:59:59.999999999"},
+		{"tuesday default last instant", "", "", "2024-05-20 23:59:59.999999999", "2024-05-14 00:00:00", "2024-05-20 23:59:59.999999999"},
+		{"tuesday default across new year", "", "", "2025-01-01 08:00:00", "2024-12-31 00:00:00", "2025-01-06 23:59:59.999999999"},
+		{"monday start", "", "monday", "2024-05-19 22:00:00", "2024-05-13 00:00:00", "2024-05-19 23:59:59.999999999"},
+		{"sunday start in UTC", "UTC", "sunday", "2024-05-18 23:00:00", "2024-05-12 00:00:00", "2024-05-18 23:59:59.999999999"},
+		{"dst spring forward", "America/New_York", "sunday", "2024-03-12 12:00:00", "2024-03-10 00:00:00", "2024-03-16 23:59:59.999999999"},
+		{"dst fall back", "America/New_York", "sunday", "2024-11-05 12:00:00", "2024-11-03 00:00:00", "2024-11-09 23:59:59.999999999"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCalendar(t, tt.timezone, tt.weekStart)
+			input := at(t, tt.input)
+			if got, want := StartOfWeek(input), at(t, tt.start); !got.Equal(want) {
+				t.Errorf("StartOfWeek(%v) = %v, want %v", input, got, want)
+			}
+			if got, want := EndOfWeek(input), at(t, tt.end); !got.Equal(want) {
+				t.Errorf("EndOfWeek(%v) = %v, want %v", input, got, want)
+			}
+		})
+	}
+}
+
+func TestStartOfWeekConvertsToCalendarZone(t *testing.T) {
+	useCalendar(t, "", "")
+	// Monday 18:00 UTC is already Tuesday 01:00 in Ho Chi Minh City, the first day of the next week
+	input := time.Date(2024, 5, 13, 18, 0, 0, 0, time.UTC)
+	if got, want := StartOfWeek(input), at(t, "2024-05-14 00:00:00"); !got.Equal(want) {
+		t.Errorf("StartOfWeek(%v) = %v, want %v", input, got, want)
+	}
+}
+
+func TestLastClosedWeek(t *testing.T) {
+	tests := []struct {
+		name      string
+		timezone  string
+		weekStart string
+		now       string
+		start     string
+		end       string
+	}{
+		{"last day of week is the current week", "", "", "2024-05-20 15:00:00", "2024-05-14 00:00:00", "2024-05-20 23:59:59.999999999"},
+		{"first day of week is the previous week", "", "", "2024-05-21 09:00:00", "2024-05-14 00:00:00", "2024-05-20 23:59:59.999999999"},
+		{"mid week is the previous week", "", "", "2024-05-23 09:00:00", "2024-05-14 00:00:00", "2024-05-20 23:59:59.999999999"},
+		{"across new year", "", "", "2025-01-02 09:00:00", "2024-12-24 00:00:00", "2024-12-30 23:59:59.999999999"},
+		{"monday start", "UTC", "monday", "2024-05-22 09:00:00", "2024-05-13 00:00:00", "2024-05-19 23:59:59.999999999"},
+		{"dst week", "America/New_York", "sunday", "2024-03-18 09:00:00", "2024-03-10 00:00:00", "2024-03-16 23:59:59.999999999"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCalendar(t, tt.timezone, tt.weekStart)
+			start, end := LastClosedWeek(at(t, tt.now))
+			if want := at(t, tt.start); !start.Equal(want) {
+				t.Errorf("start = %v, want %v", start, want)
+			}
+			if want := at(t, tt.end); !end.Equal(want) {
+				t.Errorf("end = %v, want %v", end, want)
+			}
+		})
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	tests := []struct {
+		name        string
+		timezone    string
+		weekStart   string
+		granularity Granularity
+		start       string
+		end         string
+		want        [][2]string
+	}{
+		{
+			"weekly with partial edges", "", "", Weekly, "2024-05-16 00:00:00", "2024-05-28 00:00:00",
+			[][2]string{
+				{"2024-05-16 00:00:00", "2024-05-20 23:59:59.999999999"},
+				{"2024-05-21 00:00:00", "2024-05-27 23:59:59.999999999"},
+				{"2024-05-28 00:00:00", "2024-05-28 23:59:59.999999999"},
+			},
+		},
+		{
+			"weekly across new year", "", "", Weekly, "2024-12-31 00:00:00", "2025-01-13 00:00:00",
+			[][2]string{
+				{"2024-12-31 00:00:00", "2025-01-06 23:59:59.999999999"},
+				{"2025-01-07 00:00:00", "2025-01-13 23:59:59.999999999"},
+			},
+		},
+		{
+			"weekly monday start", "UTC", "monday", Weekly, "2024-05-15 00:00:00", "2024-05-22 00:00:00",
+			[][2]string{
+				{"2024-05-15 00:00:00", "2024-05-19 23:59:59.999999999"},
+				{"2024-05-20 00:00:00", "2024-05-22 23:59:59.999999999"},
+			},
+		},
+		{
+			"weekly over dst change", "America/New_York", "sunday", Weekly, "2024-03-05 00:00:00", "2024-03-20 00:00:00",
+			[][2]string{
+				{"2024-03-05 00:00:00", "2024-03-09 23:59:59.999999999"},
+				{"2024-03-10 00:00:00", "2024-03-16 23:59:59.999999999"},
+				{"2024-03-17 00:00:00", "2024-03-20 23:59:59.999999999"},
+			},
+		},
+		{
+			"monthly across new year", "", "", Granularity{Unit: UnitMonth}, "2024-12-15 00:00:00", "2025-01-10 00:00:00",
+			[][2]string{
+				{"2024-12-15 00:00:00", "2024-12-31 23:59:59.999999999"},
+				{"2025-01-01 00:00:00", "2025-01-10 23:59:59.999999999"},
+			},
+		},
+		{
+			"whole range as given", "", "", WholeRange, "2024-05-16 10:00:00", "2024-05-28 12:00:00",
+			[][2]string{
+				{"2024-05-16 10:00:00", "2024-05-28 12:00:00"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCalendar(t, tt.timezone, tt.weekStart)
+			got := tt.granularity.Buckets(at(t, tt.start), at(t, tt.end))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d buckets %v, want %d", len(got), got, len(tt.want))
+			}
+			for i, bucket := range tt.want {
+				if !got[i][0].Equal(at(t, bucket[0])) || !got[i][1].Equal(at(t, bucket[1])) {
+					t.Errorf("bucket %d = %v, want %v", i, got[i], bucket)
+				}
+			}
+		})
+	}
+}
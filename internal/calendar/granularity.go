@@ -0,0 +1,133 @@
+package calendar
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Unit is the size of the buckets a date range is cut into
+type Unit string
+
+const (
+	UnitRange   Unit = "range"
+	UnitDay     Unit = "day"
+	UnitWeek    Unit = "week"
+	UnitMonth   Unit = "month"
+	UnitQuarter Unit = "quarter"
+	UnitSprint  Unit = "sprint"
+)
+
+var ErrInvalidGranularity = errors.New("invalid granularity, expected range, day, week, month, quarter or sprint")
+
+// Granularity cuts date ranges into buckets. Sprints are SprintDays long and counted from SprintStart,
+// which can be any day before or after the range.
+type Granularity struct {
+	Unit        Unit
+	SprintStart time.Time
+	SprintDays  int
+}
+
+var (
+	WholeRange = Granularity{Unit: UnitRange}
+	Weekly     = Granularity{Unit: UnitWeek}
+)
+
+// ParseGranularity reads a unit and, for sprints, the first day (RFC3339 or YYYY-MM-DD) and length in days.
+// An empty unit is the whole range.
+func ParseGranularity(unit, sprintStart, sprintDays string) (Granularity, error) {
+	g := Granularity{Unit: Unit(unit)}
+	switch g.Unit {
+	case "":
+		g.Unit = UnitRange
+	case UnitRange, UnitDay, UnitWeek, UnitMonth, UnitQuarter:
+	case UnitSprint:
+		days, err := strconv.Atoi(sprintDays)
+		if err != nil || days <= 0 {
+			return g, errors.New("sprintDays must be a positive number of days")
+		}
+		start, err := time.Parse(time.RFC3339, sprintStart)
+		if err != nil {
+			if start, err = time.ParseInLocation("2006-01-02", sprintStart, location); err != nil {
+				return g, errors.New("sprintStart must be a date")
+			}
+		}
+		g.SprintStart, g.SprintDays = StartOfDay(start), days
+	default:
+		return g, ErrInvalidGranularity
+	}
+	return g, nil
+}
+
+// bucketStart returns the start of the bucket containing t
+func (g Granularity) bucketStart(t time.Time) time.Time {
+	day := StartOfDay(t)
+	switch g.Unit {
+	case UnitWeek:
+		return StartOfWeek(day)
+	case UnitMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, location)
+	case UnitQuarter:
+		return time.Date(day.Year(), day.Month()-(day.Month()-1)%3, 1, 0, 0, 0, 0, location)
+	case UnitSprint:
+		days := daysBetween(g.SprintStart, day)
+		offset := days % g.SprintDays
+		if offset < 0 {
+			offset += g.SprintDays
+		}
+		return day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// nextBucket returns the start of the bucket following the one starting at start
+func (g Granularity) nextBucket(start time.Time) time.Time {
+	switch g.Unit {
+	case UnitWeek:
+		return start.AddDate(0, 0, 7)
+	case UnitMonth:
+		return start.AddDate(0, 1, 0)
+	case UnitQuarter:
+		return start.AddDate(0, 3, 0)
+	case UnitSprint:
+		return start.AddDate(0, 0, g.SprintDays)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Buckets cuts the days from start to end, both included, into consecutive buckets covering the whole range,
+// so that series have no gaps. The first and last buckets may be partial.
+// The whole range granularity returns [start, end] as given.
+func (g Granularity) Buckets(start, end time.Time) [][2]time.Time {
+	if g.Unit == UnitRange || g.Unit == "" {
+		return [][2]time.Time{{start, end}}
+	}
+	var buckets [][2]time.Time
+	last := EndOfDay(end)
+	for current := StartOfDay(start); current.Before(last); {
+		next := g.nextBucket(g.bucketStart(current))
+		bucketEnd := next.Add(-time.Nanosecond)
+		if bucketEnd.After(last) {
+			bucketEnd = last
+		}
+		buckets = append(buckets, [2]time.Time{current, bucketEnd})
+		current = next
+	}
+	return buckets
+}
+
+// SameBucket reports whether a and b fall in the same bucket
+func (g Granularity) SameBucket(a, b time.Time) bool {
+	if g.Unit == UnitRange || g.Unit == "" {
+		return true
+	}
+	return g.bucketStart(a).Equal(g.bucketStart(b))
+}
+
+// daysBetween counts calendar days from a to b, DST changes do not shift the result
+func daysBetween(a, b time.Time) int {
+	a, b = a.In(location), b.In(location)
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
@@ -0,0 +1,222 @@
+// Package config loads the settings of the server once at startup, from an optional YAML file overridden
+// by environment variables, and checks them before anything starts.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Server          Server          `yaml:"server"`
+	Mongo           Mongo           `yaml:"mongo"`
+	Asana           Asana           `yaml:"asana"`
+	Calendar        Calendar        `yaml:"calendar"`
+	Notify          Notify          `yaml:"notify"`
+	ScoreAdjustment ScoreAdjustment `yaml:"score_adjustment"`
+}
+
+type Server struct {
+	Port        int    `yaml:"port"`
+	FrontendURL string `yaml:"frontend_url"`
+}
+
+type Mongo struct {
+	URI         string                       `yaml:"uri"`
+	Database    string                       `yaml:"database"`
+	Collections collectionmodels.Collections `yaml:"collections"`
+}
+
+// Asana holds the token and the project synced for each team, teams without a project are not synced
+type Asana struct {
+	Token          string `yaml:"token"`
+	ProjectPLA     string `yaml:"project_pla"`
+	ProjectVideo   string `yaml:"project_video"`
+	ProjectArt     string `yaml:"project_art"`
+	ProjectConcept string `yaml:"project_concept"`
+}
+
+type Calendar struct {
+	TimeZone  string `yaml:"timezone"`
+	WeekStart string `yaml:"week_start"`
+}
+
+type Notify struct {
+	SlackWebhookURL string `yaml:"slack_webhook_url"`
+}
+
+type ScoreAdjustment struct {
+	RequiresApproval bool `yaml:"requires_approval"`
+}
+
+// envBinding ties an environment variable to the setting it overrides
+type envBinding struct {
+	key string
+	set func(c *Config, value string) error
+}
+
+func str(field func(c *Config) *string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+var envBindings = []envBinding{
+	{"SERVER_PORT", func(c *Config, value string) error {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("SERVER_PORT must be a number, got %q", value)
+		}
+		c.Server.Port = port
+		return nil
+	}},
+	{"FRONTEND_URL", str(func(c *Config) *string { return &c.Server.FrontendURL })},
+	{"MONGO_URI", str(func(c *Config) *string { return &c.Mongo.URI })},
+	{"MONGODB_NAME", str(func(c *Config) *string { return &c.Mongo.Database })},
+	{"ASANA_TOKEN", str(func(c *Config) *string { return &c.Asana.Token })},
+	{"ASANA_PROJECT_ID_PLA", str(func(c *Config) *string { return &c.Asana.ProjectPLA })},
+	{"ASANA_PROJECT_ID_VIDEO", str(func(c *Config) *string { return &c.Asana.ProjectVideo })},
+	{"ASANA_PROJECT_ID_ART", str(func(c *Config) *string { return &c.Asana.ProjectArt })},
+	{"ASANA_PROJECT_ID_CONCEPT", str(func(c *Config) *string { return &c.Asana.ProjectConcept })},
+	{"CALENDAR_TIMEZONE", str(func(c *Config) *string { return &c.Calendar.TimeZone })},
+	{"CALENDAR_WEEK_START", str(func(c *Config) *string { return &c.Calendar.WeekStart })},
+	{"SLACK_WEBHOOK_URL", str(func(c *Config) *string { return &c.Notify.SlackWebhookURL })},
+	{"SCORE_ADJUSTMENT_REQUIRES_APPROVAL", func(c *Config, value string) error {
+		approval, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("SCORE_ADJUSTMENT_REQUIRES_APPROVAL must be true or false, got %q", value)
+		}
+		c.ScoreAdjustment.RequiresApproval = approval
+		return nil
+	}},
+}
+
+// collectionBinding ties a collection name to its environment variable and YAML key.
+// Collections of the original deployment have no fallback and must be named, the others default to fallback.
+type collectionBinding struct {
+	key, yamlKey, fallback string
+	field                  func(c *collectionmodels.Collections) *string
+}
+
+var collectionBindings = []collectionBinding{
+	{"MONGODB_COLLECTION_COMPLETED_TASK", "completed_task", "", func(c *collectionmodels.Collections) *string { return &c.CompletedTask }},
+	{"MONGODB_COLLECTION_PERFORMANCE_SNAPSHOT", "performance_snapshot", "performance-snapshots", func(c *collectionmodels.Collections) *string { return &c.PerformanceSnapshot }},
+	{"MONGODB_COLLECTION_WEEK_CLOSURE", "week_closure", "week-closures", func(c *collectionmodels.Collections) *string { return &c.WeekClosure }},
+	{"MONGODB_COLLECTION_SCORE_ADJUSTMENT", "score_adjustment", "score-adjustments", func(c *collectionmodels.Collections) *string { return &c.ScoreAdjustment }},
+	{"MONGODB_COLLECTION_SCORE_DISPUTE", "score_dispute", "score-disputes", func(c *collectionmodels.Collections) *string { return &c.ScoreDispute }},
+	{"MONGODB_COLLECTION_STAFF_MEMBER", "staff_member", "", func(c *collectionmodels.Collections) *string { return &c.StaffMember }},
+	{"MONGODB_COLLECTION_WEEKLY_TARGET", "weekly_target", "", func(c *collectionmodels.Collections) *string { return &c.WeeklyTarget }},
+	{"MONGODB_COLLECTION_WEEKLY_TARGET_OVERRIDE", "weekly_target_override", "weekly-target-override", func(c *collectionmodels.Collections) *string { return &c.WeeklyTargetOverride }},
+	{"MONGODB_COLLECTION_MEMBER_TARGET", "member_target", "member-target", func(c *collectionmodels.Collections) *string { return &c.MemberTarget }},
+	{"MONGODB_COLLECTION_TARGET_WEIGHT", "target_weight", "target-weight", func(c *collectionmodels.Collections) *string { return &c.TargetWeight }},
+	{"MONGODB_COLLECTION_HOLIDAY", "holiday", "holiday", func(c *collectionmodels.Collections) *string { return &c.Holiday }},
+	{"MONGODB_COLLECTION_MEMBER_LEAVE", "member_leave", "member-leave", func(c *collectionmodels.Collections) *string { return &c.MemberLeave }},
+	{"MONGODB_COLLECTION_PROJECT_DETAIL", "project_detail", "", func(c *collectionmodels.Collections) *string { return &c.ProjectDetail }},
+	{"MONGODB_COLLECTION_LEVEL", "level", "", func(c *collectionmodels.Collections) *string { return &c.Level }},
+	{"MONGODB_COLLECTION_WEEKLY_ORDER", "weekly_order", "", func(c *collectionmodels.Collections) *string { return &c.WeeklyOrder }},
+	{"MONGODB_COLLECTION_ASSET_TYPE", "asset_type", "asset-types", func(c *collectionmodels.Collections) *string { return &c.AssetType }},
+	{"MONGODB_COLLECTION_CREATIVE_TOOLS", "creative_tools", "", func(c *collectionmodels.Collections) *string { return &c.CreativeTools }},
+	{"MONGODB_COLLECTION_SCHEMA_MIGRATIONS", "schema_migrations", "schema-migrations", func(c *collectionmodels.Collections) *string { return &c.SchemaMigrations }},
+}
+
+func init() {
+	for _, b := range collectionBindings {
+		field := b.field
+		envBindings = append(envBindings, envBinding{b.key, str(func(c *Config) *string { return field(&c.Mongo.Collections) })})
+	}
+}
+
+// Load reads the YAML file at path when it is not empty, then applies the environment variables that are set,
+// and validates the result. The error lists every problem found.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file: %w", err)
+		}
+		defer file.Close()
+		decoder := yaml.NewDecoder(file)
+		// A misspelled key would otherwise be ignored and leave its setting empty
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+
+	var problems []string
+	for _, b := range envBindings {
+		value := strings.TrimSpace(os.Getenv(b.key))
+		if value == "" {
+			continue
+		}
+		if err := b.set(cfg, value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	for _, b := range collectionBindings {
+		if name := b.field(&cfg.Mongo.Collections); *name == "" {
+			*name = b.fallback
+		}
+	}
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	return cfg, nil
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		problems = append(problems, "SERVER_PORT (server.port) is required, between 1 and 65535")
+	}
+	if c.Mongo.URI == "" {
+		problems = append(problems, "MONGO_URI (mongo.uri) is required")
+	}
+	if c.Mongo.Database == "" {
+		problems = append(problems, "MONGODB_NAME (mongo.database) is required")
+	}
+	missing := false
+	for _, b := range collectionBindings {
+		if *b.field(&c.Mongo.Collections) == "" {
+			problems = append(problems, fmt.Sprintf("%s (mongo.collections.%s) is required", b.key, b.yamlKey))
+			missing = true
+		}
+	}
+	if !missing {
+		if err := c.Mongo.Collections.CheckNames(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if c.Calendar.TimeZone != "" {
+		if _, err := time.LoadLocation(c.Calendar.TimeZone); err != nil {
+			problems = append(problems, fmt.Sprintf("CALENDAR_TIMEZONE (calendar.timezone) %q is not a known time zone", c.Calendar.TimeZone))
+		}
+	}
+	if c.Calendar.WeekStart != "" {
+		if _, err := calendar.ParseWeekday(c.Calendar.WeekStart); err != nil {
+			problems = append(problems, fmt.Sprintf("CALENDAR_WEEK_START (calendar.week_start) %q is not a weekday", c.Calendar.WeekStart))
+		}
+	}
+	a := c.Asana
+	if a.Token == "" && (a.ProjectPLA != "" || a.ProjectVideo != "" || a.ProjectArt != "" || a.ProjectConcept != "") {
+		problems = append(problems, "ASANA_TOKEN (asana.token) is required to sync Asana projects")
+	}
+	return problems
+}
+
+// Addr is the address the server listens on
+func (c *Config) Addr() string {
+	return ":" + strconv.Itoa(c.Server.Port)
+}
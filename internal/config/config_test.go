@@ -0,0 +1,257 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	_ "time/tzdata"
+)
+
+// requiredYAML names every setting without a default
+const requiredYAML = `
+server:
+  port: 8889
+mongo:
+  uri: mongodb://localhost:27017/?replicaSet=rs0
+  database: creative-performance
+  collections:
+    completed_task: completed-task
+    staff_member: member
+    weekly_target: weekly-target
+    project_detail: project-details
+    level: level
+    weekly_order: weekly-order
+    creative_tools: creative-tool
+`
+
+// requiredEnv sets every setting without a default from the environment
+var requiredEnv = map[string]string{
+	"SERVER_PORT":                       "8889",
+	"MONGO_URI":                         "mongodb://localhost:27017/?replicaSet=rs0",
+	"MONGODB_NAME":                      "creative-performance",
+	"MONGODB_COLLECTION_COMPLETED_TASK": "completed-task",
+	"MONGODB_COLLECTION_STAFF_MEMBER":   "member",
+	"MONGODB_COLLECTION_WEEKLY_TARGET":  "weekly-target",
+	"MONGODB_COLLECTION_PROJECT_DETAIL": "project-details",
+	"MONGODB_COLLECTION_LEVEL":          "level",
+	"MONGODB_COLLECTION_WEEKLY_ORDER":   "weekly-order",
+	"MONGODB_COLLECTION_CREATIVE_TOOLS": "creative-tool",
+}
+
+// clearEnv blanks every variable Load reads for the test, empty values are ignored like unset ones
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, b := range envBindings {
+		t.Setenv(b.key, "")
+	}
+}
+
+func setEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+// writeConfig writes a YAML config file for the test and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		// emptyFile loads an empty YAML file instead of none when yaml is empty
+		emptyFile bool
+		env       map[string]string
+		check     func(t *testing.T, c *Config)
+	}{
+		{
+			name: "yaml only",
+			yaml: requiredYAML + `
+calendar:
+  timezone: Asia/Ho_Chi_Minh
+  week_start: tuesday
+score_adjustment:
+  requires_approval: true
+`,
+			check: func(t *testing.T, c *Config) {
+				if c.Server.Port != 8889 || c.Mongo.Database != "creative-performance" || c.Mongo.Collections.StaffMember != "member" {
+					t.Errorf("YAML settings not loaded: %+v", c)
+				}
+				if c.Calendar.WeekStart != "tuesday" || !c.ScoreAdjustment.RequiresApproval {
+					t.Errorf("calendar or score adjustment not loaded: %+v %+v", c.Calendar, c.ScoreAdjustment)
+				}
+			},
+		},
+		{
+			name: "environment only",
+			env:  requiredEnv,
+			check: func(t *testing.T, c *Config) {
+				if c.Server.Port != 8889 || c.Mongo.URI != requiredEnv["MONGO_URI"] || c.Mongo.Collections.CreativeTools != "creative-tool" {
+					t.Errorf("environment settings not loaded: %+v", c)
+				}
+			},
+		},
+		{
+			name: "environment overrides yaml",
+			yaml: requiredYAML + `
+score_adjustment:
+  requires_approval: true
+`,
+			env: map[string]string{
+				"SERVER_PORT":                        "9000",
+				"MONGODB_NAME":                       "staging",
+				"MONGODB_COLLECTION_LEVEL":           "levels-v2",
+				"MONGODB_COLLECTION_HOLIDAY":         "holidays-v2",
+				"SCORE_ADJUSTMENT_REQUIRES_APPROVAL": "false",
+				"CALENDAR_WEEK_START":                " monday ",
+			},
+			check: func(t *testing.T, c *Config) {
+				if c.Server.Port != 9000 || c.Mongo.Database != "staging" {
+					t.Errorf("environment did not override server and database: %+v %+v", c.Server, c.Mongo)
+				}
+				if c.Mongo.Collections.Level != "levels-v2" || c.Mongo.Collections.Holiday != "holidays-v2" {
+					t.Errorf("environment did not override collections: %+v", c.Mongo.Collections)
+				}
+				if c.ScoreAdjustment.RequiresApproval {
+					t.Error("SCORE_ADJUSTMENT_REQUIRES_APPROVAL=false did not override the YAML")
+				}
+				if c.Calendar.WeekStart != "monday" {
+					t.Errorf("week start = %q, want the trimmed environment value", c.Calendar.WeekStart)
+				}
+				if c.Mongo.URI != "mongodb://localhost:27017/?replicaSet=rs0" {
+					t.Errorf("URI = %q, want the YAML value when the environment does not set it", c.Mongo.URI)
+				}
+			},
+		},
+		{
+			name: "optional collections fall back to their defaults",
+			yaml: requiredYAML,
+			check: func(t *testing.T, c *Config) {
+				for _, b := range collectionBindings {
+					if b.fallback == "" {
+						continue
+					}
+					if got := *b.field(&c.Mongo.Collections); got != b.fallback {
+						t.Errorf("%s = %q, want the fallback %q", b.key, got, b.fallback)
+					}
+				}
+			},
+		},
+		{
+			name:      "empty yaml file",
+			emptyFile: true,
+			env:       requiredEnv,
+			check: func(t *testing.T, c *Config) {
+				if c.Mongo.Database != "creative-performance" {
+					t.Errorf("database = %q, want the environment value", c.Mongo.Database)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			setEnv(t, tt.env)
+			path := ""
+			if tt.yaml != "" || tt.emptyFile {
+				path = writeConfig(t, tt.yaml)
+			}
+			c, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestLoadProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		env      map[string]string
+		problems []string
+	}{
+		{
+			name: "nothing set lists every required setting",
+			problems: []string{
+				"SERVER_PORT (server.port) is required",
+				"MONGO_URI (mongo.uri) is required",
+				"MONGODB_NAME (mongo.database) is required",
+				"MONGODB_COLLECTION_COMPLETED_TASK (mongo.collections.completed_task) is required",
+				"MONGODB_COLLECTION_CREATIVE_TOOLS (mongo.collections.creative_tools) is required",
+			},
+		},
+		{
+			name:     "misspelled yaml key",
+			yaml:     requiredYAML + "calender:\n  timezone: UTC\n",
+			problems: []string{"parsing config file", "calender"},
+		},
+		{
+			name:     "invalid numbers and booleans from the environment",
+			yaml:     requiredYAML,
+			env:      map[string]string{"SERVER_PORT": "eighty", "SCORE_ADJUSTMENT_REQUIRES_APPROVAL": "maybe"},
+			problems: []string{`SERVER_PORT must be a number, got "eighty"`, `SCORE_ADJUSTMENT_REQUIRES_APPROVAL must be true or false, got "maybe"`},
+		},
+		{
+			name:     "port out of range",
+			yaml:     requiredYAML,
+			env:      map[string]string{"SERVER_PORT": "70000"},
+			problems: []string{"between 1 and 65535"},
+		},
+		{
+			name:     "unknown time zone and weekday",
+			yaml:     requiredYAML,
+			env:      map[string]string{"CALENDAR_TIMEZONE": "Mars/Olympus", "CALENDAR_WEEK_START": "someday"},
+			problems: []string{`"Mars/Olympus" is not a known time zone`, `"someday" is not a weekday`},
+		},
+		{
+			name:     "two collections with the same name",
+			yaml:     requiredYAML,
+			env:      map[string]string{"MONGODB_COLLECTION_LEVEL": "member"},
+			problems: []string{"invalid collection names", `are both "member"`},
+		},
+		{
+			name:     "asana projects without a token",
+			yaml:     requiredYAML,
+			env:      map[string]string{"ASANA_PROJECT_ID_ART": "1200"},
+			problems: []string{"ASANA_TOKEN (asana.token) is required"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			setEnv(t, tt.env)
+			path := ""
+			if tt.yaml != "" {
+				path = writeConfig(t, tt.yaml)
+			}
+			_, err := Load(path)
+			if err == nil {
+				t.Fatal("Load succeeded, want an error")
+			}
+			for _, problem := range tt.problems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("error does not mention %q:\n%v", problem, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	clearEnv(t)
+	setEnv(t, requiredEnv)
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil || !strings.Contains(err.Error(), "reading config file") {
+		t.Errorf("Load of a missing file error = %v, want a read error", err)
+	}
+}
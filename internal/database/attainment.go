@@ -0,0 +1,304 @@
+package db_handler
+
+import (
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"slices"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AttainmentPeriod is the points of a team or member in one period against the target in force
+type AttainmentPeriod struct {
+	StartDate  time.Time `bson:"start_date"`
+	EndDate    time.Time `bson:"end_date"`
+	Point      float64   `bson:"point"`
+	Target     float64   `bson:"target"`
+	Percentage float64   `bson:"percentage"`
+	Attained   bool      `bson:"attained"`
+	// WorkingDays and AvailableDays are person-days for teams, the target is pro-rated by their ratio.
+	// WorkingDays span whole weeks and AvailableDays only the days of the range, so partial weeks get part of the target.
+	WorkingDays   float64 `bson:"working_days"`
+	AvailableDays float64 `bson:"available_days"`
+}
+
+// Attainment is the attainment of one team or member over the whole range.
+// Streaks count consecutive attained periods, periods without a target neither extend nor break them.
+type Attainment struct {
+	Identifier    string             `bson:"identifier"`
+	Name          string             `bson:"name,omitempty"`
+	Teams         []string           `bson:"teams,omitempty"`
+	Periods       []AttainmentPeriod `bson:"periods"`
+	TotalPoint    float64            `bson:"total_point"`
+	TotalTarget   float64            `bson:"total_target"`
+	Percentage    float64            `bson:"percentage"`
+	CurrentStreak int                `bson:"current_streak"`
+	LongestStreak int                `bson:"longest_streak"`
+	Rank          int                `bson:"rank"`
+}
+
+// AttainmentReport holds the teams and members leaderboards, each sorted by rank
+type AttainmentReport struct {
+	Granularity calendar.Unit `bson:"granularity"`
+	Teams       []Attainment  `bson:"teams"`
+	Members     []Attainment  `bson:"members"`
+}
+
+// GetTargetAttainment compares the points of the given teams and their members with the weekly targets in force.
+// A member's weekly target is their explicit MemberTarget, or else their weighted share of what is left of
+// each team target once the explicit targets of the team's members are taken out.
+// Targets are then pro-rated by available working days: a member's by their days off holidays and leave,
+// a team's by the available person-days of its members. The first and last weeks only count the days of the range.
+// Targets are weekly so weeks are grouped by month or quarter, a week belongs to the period it starts in.
+func GetTargetAttainment(client *mongo.Client, dbName string, teams []string, startDate, endDate time.Time, unit calendar.Unit) (*AttainmentReport, error) {
+	if unit == "" {
+		unit = calendar.UnitWeek
+	}
+	if unit != calendar.UnitWeek && unit != calendar.UnitMonth && unit != calendar.UnitQuarter {
+		return nil, calendar.ErrInvalidGranularity
+	}
+	granularity := calendar.Granularity{Unit: unit}
+
+	weeks := calendar.SplitWeeks(startDate, endDate)
+
+	effective, err := GetEffectiveWeeklyTargets(client, dbName, teams, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	type teamData struct {
+		team    string
+		targets []collectionmodels.EffectiveWeeklyTarget
+		points  map[time.Time]float64
+		members []*collectionmodels.Member
+	}
+	teamPoints, err := weeklyPoints(client, dbName, collections, teams, startDate, endDate, collectionmodels.KindTeam)
+	if err != nil {
+		return nil, err
+	}
+	var data []teamData
+	var emails []string
+	for i, team := range teams {
+		// Effective targets are ordered by team then week
+		targets := effective[i*len(weeks) : (i+1)*len(weeks)]
+		points := teamPoints[team]
+		// Former members still count in the weeks they belonged to the team
+		teamMembers, err := GetMembersByTeam(dbName, collections.StaffMember, team, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range teamMembers {
+			if !slices.Contains(emails, m.Email) {
+				emails = append(emails, m.Email)
+			}
+		}
+		data = append(data, teamData{team: team, targets: targets, points: points, members: teamMembers})
+	}
+
+	explicitTargets, err := collectionmodels.GetMemberTargets(client, dbName, collections.MemberTarget, emails, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	weights, err := collectionmodels.GetAllTargetWeights(client, dbName, collections.TargetWeight)
+	if err != nil {
+		return nil, err
+	}
+	avail, err := loadAvailability(client, dbName, emails, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AttainmentReport{Granularity: unit}
+	members := map[string]*collectionmodels.Member{}
+	memberTargets := map[string][]float64{}
+	memberDays := map[string][][2]float64{}
+	memberTeams := map[string][]string{}
+	var memberOrder []string
+
+	for _, d := range data {
+		weight := collectionmodels.TargetWeightForTeam(weights, d.team)
+		teamPeriods := make([]AttainmentPeriod, len(weeks))
+		for i, week := range weeks {
+			target := float64(d.targets[i].Point)
+			period := AttainmentPeriod{StartDate: week[0], EndDate: week[1], Point: d.points[week[0]]}
+
+			remaining := target
+			var split []*collectionmodels.Member
+			totalWeight := 0.0
+			for _, m := range d.members {
+				teamsAt := m.TeamsAt(week[0])
+				if !slices.Contains(teamsAt, d.team) {
+					continue
+				}
+				if _, ok := members[m.Email]; !ok {
+					members[m.Email] = m
+					memberTargets[m.Email] = make([]float64, len(weeks))
+					memberDays[m.Email] = make([][2]float64, len(weeks))
+					memberOrder = append(memberOrder, m.Email)
+				}
+				working, available := avail.memberDays(m.Email, week)
+				memberDays[m.Email][i] = [2]float64{working, available}
+				period.WorkingDays += working
+				period.AvailableDays += available
+				if !slices.Contains(memberTeams[m.Email], d.team) {
+					memberTeams[m.Email] = append(memberTeams[m.Email], d.team)
+				}
+				if explicit, ok := memberTargetAt(explicitTargets, m.Email, week[0]); ok {
+					// A member of several teams takes an equal part of their target from each
+					memberTargets[m.Email][i] = float64(explicit) * ratio(working, available)
+					remaining -= float64(explicit) / float64(len(teamsAt))
+					continue
+				}
+				split = append(split, m)
+				totalWeight += weight.MemberWeight(m, d.team, week[0])
+			}
+			if period.WorkingDays == 0 {
+				// No members that week, only holidays count
+				days, working := avail.openDays(week)
+				period.WorkingDays, period.AvailableDays = working, float64(len(days))
+			}
+			period.Target = target * ratio(period.WorkingDays, period.AvailableDays)
+			teamPeriods[i] = period
+
+			if remaining < 0 {
+				remaining = 0
+			}
+			if totalWeight == 0 {
+				continue
+			}
+			for _, m := range split {
+				days := memberDays[m.Email][i]
+				memberTargets[m.Email][i] += remaining * weight.MemberWeight(m, d.team, week[0]) / totalWeight * ratio(days[0], days[1])
+			}
+		}
+		report.Teams = append(report.Teams, newAttainment(d.team, teamPeriods, granularity))
+	}
+
+	memberPoints, err := weeklyPoints(client, dbName, collections, memberOrder, startDate, endDate, collectionmodels.KindMember)
+	if err != nil {
+		return nil, err
+	}
+	for _, email := range memberOrder {
+		points := memberPoints[email]
+		periods := make([]AttainmentPeriod, len(weeks))
+		for i, week := range weeks {
+			periods[i] = AttainmentPeriod{
+				StartDate:     week[0],
+				EndDate:       week[1],
+				Point:         points[week[0]],
+				Target:        memberTargets[email][i],
+				WorkingDays:   memberDays[email][i][0],
+				AvailableDays: memberDays[email][i][1],
+			}
+		}
+		a := newAttainment(email, periods, granularity)
+		a.Name = members[email].Name
+		a.Teams = memberTeams[email]
+		report.Members = append(report.Members, a)
+	}
+
+	rankAttainments(report.Teams)
+	rankAttainments(report.Members)
+	return report, nil
+}
+
+// weeklyPoints returns the total points of each identifier keyed by the start of each week
+func weeklyPoints(client *mongo.Client, dbName string, collections collectionmodels.Collections, identifiers []string, startDate, endDate time.Time, kind collectionmodels.IdentifierKind) (map[string]map[time.Time]float64, error) {
+	res, err := GetPerformancePointsBatch(client, dbName, collections, identifiers, startDate, endDate, kind, calendar.Weekly)
+	if err != nil {
+		return nil, err
+	}
+	points := map[string]map[time.Time]float64{}
+	for _, r := range res {
+		id := r.TotalPerformancePoint.Identifier
+		if points[id] == nil {
+			points[id] = map[time.Time]float64{}
+		}
+		points[id][r.StartDate] = r.TotalPerformancePoint.TotalPerformancePoint
+	}
+	return points, nil
+}
+
+// memberTargetAt returns the point of the explicit target of email covering t
+func memberTargetAt(targets []collectionmodels.MemberTarget, email string, t time.Time) (int, bool) {
+	for _, target := range targets {
+		if target.Email == email && !t.Before(target.DateFrom) && !t.After(target.DateTo) {
+			return target.Point, true
+		}
+	}
+	return 0, false
+}
+
+// newAttainment groups weekly periods by granularity and computes totals and streaks
+func newAttainment(identifier string, weeks []AttainmentPeriod, granularity calendar.Granularity) Attainment {
+	var periods []AttainmentPeriod
+	for _, w := range weeks {
+		n := len(periods)
+		if n > 0 && granularity.SameBucket(periods[n-1].StartDate, w.StartDate) {
+			periods[n-1].EndDate = w.EndDate
+			periods[n-1].Point += w.Point
+			periods[n-1].Target += w.Target
+			periods[n-1].WorkingDays += w.WorkingDays
+			periods[n-1].AvailableDays += w.AvailableDays
+			continue
+		}
+		periods = append(periods, w)
+	}
+
+	a := Attainment{Identifier: identifier}
+	streak := 0
+	for i := range periods {
+		p := &periods[i]
+		p.Percentage = percentage(p.Point, p.Target)
+		p.Attained = p.Target > 0 && p.Point >= p.Target
+		a.TotalPoint += p.Point
+		a.TotalTarget += p.Target
+
+		if p.Target == 0 {
+			continue
+		}
+		if p.Attained {
+			streak++
+			if streak > a.LongestStreak {
+				a.LongestStreak = streak
+			}
+		} else {
+			streak = 0
+		}
+	}
+	a.CurrentStreak = streak
+	a.Percentage = percentage(a.TotalPoint, a.TotalTarget)
+	a.Periods = periods
+	return a
+}
+
+func percentage(point, target float64) float64 {
+	if target <= 0 {
+		return 0
+	}
+	return point / target * 100
+}
+
+// rankAttainments sorts by percentage attained then total points, those without any target come last.
+// Equal entries share the same rank.
+func rankAttainments(list []Attainment) {
+	less := func(a, b Attainment) bool {
+		if (a.TotalTarget > 0) != (b.TotalTarget > 0) {
+			return a.TotalTarget > 0
+		}
+		if a.Percentage != b.Percentage {
+			return a.Percentage > b.Percentage
+		}
+		return a.TotalPoint > b.TotalPoint
+	}
+	sort.SliceStable(list, func(i, j int) bool { return less(list[i], list[j]) })
+	for i := range list {
+		if i > 0 && !less(list[i-1], list[i]) {
+			list[i].Rank = list[i-1].Rank
+			continue
+		}
+		list[i].Rank = i + 1
+	}
+}
@@ -0,0 +1,101 @@
+package db_handler
+
+import (
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// WorkingWeekdays are the days of a week counted when pro-rating targets
+var WorkingWeekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// availability holds the company holidays and member leaves of a date range
+type availability struct {
+	holidays []collectionmodels.Holiday
+	leaves   []collectionmodels.MemberLeave
+}
+
+func loadAvailability(client *mongo.Client, dbName string, emails []string, startDate, endDate time.Time) (*availability, error) {
+	// Weeks are pro-rated as a whole, so load the full first and last weeks too
+	from, to := calendar.StartOfWeek(startDate), calendar.EndOfWeek(endDate)
+	holidays, err := collectionmodels.GetHolidays(client, dbName, collections.Holiday, from, to)
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := collectionmodels.GetMemberLeaves(client, dbName, collections.MemberLeave, emails, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &availability{holidays: holidays, leaves: leaves}, nil
+}
+
+// dayKey compares days by their date in the calendar time zone
+func dayKey(t time.Time) string {
+	return t.In(calendar.Location()).Format("2006-01-02")
+}
+
+func covers(dateFrom, dateTo, day time.Time) bool {
+	key := dayKey(day)
+	return dayKey(dateFrom) <= key && key <= dayKey(dateTo)
+}
+
+// openDays returns the working days of a week bucket that are not company holidays, and the number of working days
+// of the whole week. Partial weeks at the edges of a range only count the days they cover, so their target is
+// pro-rated by the share of the week they cover.
+func (a *availability) openDays(week [2]time.Time) ([]time.Time, float64) {
+	start := calendar.StartOfWeek(week[0])
+	var days []time.Time
+	working := 0.0
+	for i := 0; i < 7; i++ {
+		day := start.AddDate(0, 0, i)
+		if !slices.Contains(WorkingWeekdays, day.Weekday()) {
+			continue
+		}
+		working++
+		if !covers(week[0], week[1], day) {
+			continue
+		}
+		holiday := false
+		for _, h := range a.holidays {
+			if covers(h.DateFrom, h.DateTo, day) {
+				holiday = true
+				break
+			}
+		}
+		if !holiday {
+			days = append(days, day)
+		}
+	}
+	return days, working
+}
+
+// memberDays returns the working days of the whole week and how many days of the bucket email is available,
+// off holidays and leaves
+func (a *availability) memberDays(email string, week [2]time.Time) (float64, float64) {
+	days, working := a.openDays(week)
+	available := 0.0
+	for _, day := range days {
+		onLeave := false
+		for _, l := range a.leaves {
+			if l.Email == email && covers(l.DateFrom, l.DateTo, day) {
+				onLeave = true
+				break
+			}
+		}
+		if !onLeave {
+			available++
+		}
+	}
+	return working, available
+}
+
+// ratio is the share of available days, 1 when the week has no working day
+func ratio(working, available float64) float64 {
+	if working == 0 {
+		return 1
+	}
+	return available / working
+}
@@ -0,0 +1,119 @@
+package collectionmodels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrUnknownAssetType = errors.New("unknown asset type")
+
+// AssetType is a kind of deliverable that weekly orders ask for, made by one team.
+// Keyword names the asset type in task names, it tells apart the asset types of a team that has several.
+type AssetType struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty"`
+	Key     string             `bson:"key"`
+	Name    string             `bson:"name"`
+	Team    string             `bson:"team"`
+	Keyword string             `bson:"keyword,omitempty"`
+
+	SoftDelete `bson:",inline"`
+}
+
+// GetAllAssetTypes returns the asset type catalog, deleted ones only when includeDeleted is set
+func GetAllAssetTypes(client *mongo.Client, dbName, collectionName string, includeDeleted bool) ([]AssetType, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	cursor, err := collection.Find(ctx, activeFilter(bson.M{}, includeDeleted))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var results []AssetType
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func AddAssetType(client *mongo.Client, dbName, collectionName string, assetType *AssetType) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	return insertOrReplaceDeleted(ctx, collection, bson.M{"key": assetType.Key}, assetType)
+}
+
+func UpdateAssetType(client *mongo.Client, dbName, collectionName string, assetType *AssetType) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	res, err := collection.UpdateOne(ctx,
+		activeFilter(bson.M{"key": assetType.Key}, false),
+		bson.M{"$set": bson.M{"name": assetType.Name, "team": assetType.Team, "keyword": assetType.Keyword}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteAssetType removes an asset type from the catalog, orders already asking for it keep their items
+func DeleteAssetType(client *mongo.Client, dbName, collectionName, key, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	return softDeleteOne(ctx, collection, bson.M{"key": key}, deletedBy)
+}
+
+func RestoreAssetType(client *mongo.Client, dbName, collectionName, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	return restoreOne(ctx, collection, bson.M{"key": key})
+}
+
+// ResolveOrderItems checks every item asks for a positive quantity of an asset type of the catalog,
+// with a known priority. Items without a team get the team of their asset type, those without a priority
+// the normal one.
+func ResolveOrderItems(catalog []AssetType, items []OrderItem) error {
+	seen := map[string]bool{}
+	for i := range items {
+		item := &items[i]
+		var assetType *AssetType
+		for j := range catalog {
+			if catalog[j].Key == item.AssetType {
+				assetType = &catalog[j]
+				break
+			}
+		}
+		if assetType == nil {
+			return fmt.Errorf("%w: %q", ErrUnknownAssetType, item.AssetType)
+		}
+		if item.Team == "" {
+			item.Team = assetType.Team
+		}
+		if item.Priority == "" {
+			item.Priority = PriorityNormal
+		}
+		if !containsString(OrderPriorities, item.Priority) {
+			return fmt.Errorf("%w: %q", ErrInvalidOrderItem, item.Priority)
+		}
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity of %s must be positive", ErrInvalidOrderItem, item.AssetType)
+		}
+		if seen[item.AssetType+"|"+item.Team] {
+			return fmt.Errorf("%w: %s for %s is listed twice", ErrInvalidOrderItem, item.AssetType, item.Team)
+		}
+		seen[item.AssetType+"|"+item.Team] = true
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package collectionmodels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Collections holds the name of every collection of the database. Aggregation pipelines take their
+// $lookup sources from it, so every stage reads the collections the rest of the code writes.
+type Collections struct {
+	CompletedTask        string `yaml:"completed_task"`
+	PerformanceSnapshot  string `yaml:"performance_snapshot"`
+	WeekClosure          string `yaml:"week_closure"`
+	ScoreAdjustment      string `yaml:"score_adjustment"`
+	ScoreDispute         string `yaml:"score_dispute"`
+	StaffMember          string `yaml:"staff_member"`
+	WeeklyTarget         string `yaml:"weekly_target"`
+	WeeklyTargetOverride string `yaml:"weekly_target_override"`
+	MemberTarget         string `yaml:"member_target"`
+	TargetWeight         string `yaml:"target_weight"`
+	Holiday              string `yaml:"holiday"`
+	MemberLeave          string `yaml:"member_leave"`
+	ProjectDetail        string `yaml:"project_detail"`
+	Level                string `yaml:"level"`
+	WeeklyOrder          string `yaml:"weekly_order"`
+	AssetType            string `yaml:"asset_type"`
+	CreativeTools        string `yaml:"creative_tools"`
+	SchemaMigrations     string `yaml:"schema_migrations"`
+}
+
+func (c Collections) named() []struct{ field, name string } {
+	return []struct{ field, name string }{
+		{"CompletedTask", c.CompletedTask},
+		{"PerformanceSnapshot", c.PerformanceSnapshot},
+		{"WeekClosure", c.WeekClosure},
+		{"ScoreAdjustment", c.ScoreAdjustment},
+		{"ScoreDispute", c.ScoreDispute},
+		{"StaffMember", c.StaffMember},
+		{"WeeklyTarget", c.WeeklyTarget},
+		{"WeeklyTargetOverride", c.WeeklyTargetOverride},
+		{"MemberTarget", c.MemberTarget},
+		{"TargetWeight", c.TargetWeight},
+		{"Holiday", c.Holiday},
+		{"MemberLeave", c.MemberLeave},
+		{"ProjectDetail", c.ProjectDetail},
+		{"Level", c.Level},
+		{"WeeklyOrder", c.WeeklyOrder},
+		{"AssetType", c.AssetType},
+		{"CreativeTools", c.CreativeTools},
+		{"SchemaMigrations", c.SchemaMigrations},
+	}
+}
+
+// CheckNames checks every name is set and used once
+func (c Collections) CheckNames() error {
+	var problems []string
+	used := map[string]string{}
+	for _, n := range c.named() {
+		if n.name == "" {
+			problems = append(problems, n.field+" is not set")
+			continue
+		}
+		if other, ok := used[n.name]; ok {
+			problems = append(problems, fmt.Sprintf("%s and %s are both %q", other, n.field, n.name))
+			continue
+		}
+		used[n.name] = n.field
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid collection names: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// Validate checks the names with CheckNames. On a database that already holds data, the collections
+// pipelines read from must exist: a $lookup on a missing collection silently matches nothing.
+// Collections that are only written to are created on first use and are not checked.
+func (c Collections) Validate(ctx context.Context, database *mongo.Database) error {
+	if err := c.CheckNames(); err != nil {
+		return err
+	}
+
+	existing, err := database.ListCollectionNames(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		// A new database, nothing to read yet
+		return nil
+	}
+	var problems []string
+	for _, n := range c.named() {
+		switch n.name {
+		case c.CompletedTask, c.Level, c.CreativeTools, c.ProjectDetail, c.WeeklyOrder:
+			if !containsString(existing, n.name) {
+				problems = append(problems, fmt.Sprintf("%q (%s) does not exist", n.name, n.field))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("missing collections in %s: %s", database.Name(), strings.Join(problems, ", "))
+	}
+	return nil
+}
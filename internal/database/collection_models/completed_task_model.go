@@ -10,6 +10,8 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// CompletedTask is a task synced from Asana. Project is the game name as given in Asana, ProjectID the catalog
+// project it resolves to, unset while the name is unknown to the catalog.
 type CompletedTask struct {
 	ID         primitive.ObjectID `bson:"_id,omitempty"`
 	TaskID     string             `bson:"id"`
@@ -19,6 +21,7 @@ type CompletedTask struct {
 	Level      int                `bson:"level"`
 	TaskType   string             `bson:"task_type"`
 	Project    string             `bson:"project"`
+	ProjectID  int                `bson:"project_id,omitempty"`
 	Team       string             `bson:"team"`
 	DoneDate   time.Time          `bson:"done_date"`
 }
@@ -45,8 +48,6 @@ func InsertCompletedTaskToDataBase(client *mongo.Client, dbName, collectionName
 }
 
 func GetCompletedTasksByDateRange(client *mongo.Client, dbName, collectionName string, isTeam bool, identifier string, startDate, endDate time.Time) ([]CompletedTask, error) {
-	collection := client.Database(dbName).Collection(collectionName)
-
 	var indentifierKey string
 	if isTeam {
 		indentifierKey = "team"
@@ -61,6 +62,11 @@ func GetCompletedTasksByDateRange(client *mongo.Client, dbName, collectionName s
 			"$lte": endDate,
 		},
 	}
+	return GetCompletedTasksByFilter(client, dbName, collectionName, filter)
+}
+
+func GetCompletedTasksByFilter(client *mongo.Client, dbName, collectionName string, filter bson.M) ([]CompletedTask, error) {
+	collection := client.Database(dbName).Collection(collectionName)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -84,3 +90,102 @@ func GetCompletedTasksByDateRange(client *mongo.Client, dbName, collectionName s
 	}
 	return tasks, nil
 }
+
+// UpdateCompletedTaskScoring sets the level and tools of a task
+func UpdateCompletedTaskScoring(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, level int, tools []int) error {
+	collection := client.Database(dbName).Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"level": level, "tool": tools}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// UnresolvedProject is a project name of completed tasks that matches no project of the catalog
+type UnresolvedProject struct {
+	Name          string    `bson:"_id"`
+	Tasks         int       `bson:"tasks"`
+	Teams         []string  `bson:"teams"`
+	FirstDoneDate time.Time `bson:"first_done_date"`
+	LastDoneDate  time.Time `bson:"last_done_date"`
+}
+
+// unresolvedProjectFilter matches the tasks naming a project that is not resolved to the catalog
+var unresolvedProjectFilter = bson.M{"project": bson.M{"$nin": bson.A{"", nil}}, "project_id": nil}
+
+// GetUnresolvedTaskProjects lists the project names of completed tasks that are not in the catalog, most tasks first
+func GetUnresolvedTaskProjects(client *mongo.Client, dbName, collectionName string) ([]UnresolvedProject, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return unresolvedProjects(ctx, client.Database(dbName).Collection(collectionName))
+}
+
+func unresolvedProjects(ctx context.Context, collection *mongo.Collection) ([]UnresolvedProject, error) {
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: unresolvedProjectFilter}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$project"},
+			{Key: "tasks", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "teams", Value: bson.D{{Key: "$addToSet", Value: "$team"}}},
+			{Key: "first_done_date", Value: bson.D{{Key: "$min", Value: "$done_date"}}},
+			{Key: "last_done_date", Value: bson.D{{Key: "$max", Value: "$done_date"}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "tasks", Value: -1}, {Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := []UnresolvedProject{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ResolvedTasks counts the tasks ResolveTaskProjects linked to a project and the range of their done dates,
+// whose project snapshots no longer hold
+type ResolvedTasks struct {
+	Count    int64
+	DoneFrom time.Time
+	DoneTo   time.Time
+}
+
+// ResolveTaskProjects sets the project of the unresolved completed tasks whose name the catalog now knows,
+// after a project or an alias was added.
+func ResolveTaskProjects(client *mongo.Client, dbName, collectionName string, catalog *ProjectCatalog) (*ResolvedTasks, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	projects, err := unresolvedProjects(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	resolved := &ResolvedTasks{}
+	for _, p := range projects {
+		id, ok := catalog.Resolve(p.Name)
+		if !ok {
+			continue
+		}
+		filter := bson.M{"project": p.Name, "project_id": nil}
+		res, err := collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"project_id": id}})
+		if err != nil {
+			return resolved, err
+		}
+		if res.ModifiedCount == 0 {
+			continue
+		}
+		if resolved.Count == 0 || p.FirstDoneDate.Before(resolved.DoneFrom) {
+			resolved.DoneFrom = p.FirstDoneDate
+		}
+		if resolved.Count == 0 || p.LastDoneDate.After(resolved.DoneTo) {
+			resolved.DoneTo = p.LastDoneDate
+		}
+		resolved.Count += res.ModifiedCount
+	}
+	return resolved, nil
+}
@@ -16,6 +16,8 @@ type CreativeTool struct {
 	Type     string             `bson:"type"`
 	Point    []float64          `bson:"point"`
 	Index    int                `bson:"index"`
+
+	SoftDelete `bson:",inline"`
 }
 
 func GetCreativeToolByTeam(client *mongo.Client, dbName, collectionName, team string) (*CreativeTool, error) {
@@ -23,7 +25,7 @@ func GetCreativeToolByTeam(client *mongo.Client, dbName, collectionName, team st
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
 	var tool CreativeTool
-	err := collection.FindOne(ctx, bson.M{"team": team}).Decode(&tool)
+	err := collection.FindOne(ctx, activeFilter(bson.M{"team": team}, false)).Decode(&tool)
 	if err != nil {
 		return nil, err
 	}
@@ -34,8 +36,7 @@ func AddCreativeTool(client *mongo.Client, dbName, collectionName string, tool *
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.InsertOne(ctx, tool)
-	return err
+	return insertOrReplaceDeleted(ctx, collection, bson.M{"team": tool.Team, "tool_name": tool.ToolName}, tool)
 }
 
 func UpdateCreativeTool(client *mongo.Client, dbName, collectionName string, tool *CreativeTool) error {
@@ -43,25 +44,32 @@ func UpdateCreativeTool(client *mongo.Client, dbName, collectionName string, too
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
 	_, err := collection.UpdateOne(ctx,
-		bson.M{"team": tool.Team, "tool_name": tool.ToolName},
+		activeFilter(bson.M{"team": tool.Team, "tool_name": tool.ToolName}, false),
 		bson.M{"$set": bson.M{"type": tool.Type, "point": tool.Point}},
 	)
 	return err
 }
 
-func DeleteCreativeTool(client *mongo.Client, dbName, collectionName, team, toolName string) error {
+func DeleteCreativeTool(client *mongo.Client, dbName, collectionName, team, toolName, deletedBy string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.DeleteOne(ctx, bson.M{"team": team, "tool_name": toolName})
-	return err
+	return softDeleteOne(ctx, collection, bson.M{"team": team, "tool_name": toolName}, deletedBy)
+}
+
+func RestoreCreativeTool(client *mongo.Client, dbName, collectionName, team, toolName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	return restoreOne(ctx, collection, bson.M{"team": team, "tool_name": toolName})
 }
 
-func GetAllCreativeTools(client *mongo.Client, dbName, collectionName string) ([]CreativeTool, error) {
+// GetAllCreativeTools returns the tools used for scoring, deleted ones only when includeDeleted is set
+func GetAllCreativeTools(client *mongo.Client, dbName, collectionName string, includeDeleted bool) ([]CreativeTool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	cursor, err := collection.Find(ctx, bson.M{})
+	cursor, err := collection.Find(ctx, activeFilter(bson.M{}, includeDeleted))
 	if err != nil {
 		return nil, err
 	}
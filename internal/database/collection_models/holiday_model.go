@@ -0,0 +1,123 @@
+package collectionmodels
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Holiday is a company holiday from DateFrom to DateTo, both days included.
+// UID is the ICS event it was imported from, if any.
+type Holiday struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty"`
+	Name     string             `bson:"name"`
+	DateFrom time.Time          `bson:"date_from"`
+	DateTo   time.Time          `bson:"date_to"`
+	UID      string             `bson:"ics_uid,omitempty"`
+
+	SoftDelete `bson:",inline"`
+}
+
+// GetHolidays returns the holidays overlapping [dateFrom, dateTo]
+func GetHolidays(client *mongo.Client, dbName, collName string, dateFrom, dateTo time.Time) ([]Holiday, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	filter := bson.M{"date_from": bson.M{"$lte": dateTo}, "date_to": bson.M{"$gte": dateFrom}}
+	cursor, err := collection.Find(ctx, activeFilter(filter, false))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var holidays []Holiday
+	if err = cursor.All(ctx, &holidays); err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+var holidaySortFields = []string{"name", "date_from", "date_to"}
+
+// GetHolidaysPage returns one page of holidays matching the spec, plus the cursor of the next page.
+// The start week range keeps every holiday overlapping it.
+func GetHolidaysPage(client *mongo.Client, dbName, collName string, spec QuerySpec) ([]Holiday, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	filter := bson.M{}
+	if spec.StartWeekFrom != nil {
+		filter["date_to"] = bson.M{"$gte": *spec.StartWeekFrom}
+	}
+	if spec.StartWeekTo != nil {
+		filter["date_from"] = bson.M{"$lte": *spec.StartWeekTo}
+	}
+	return findPage[Holiday](ctx, collection, activeFilter(filter, spec.IncludeDeleted), spec, holidaySortFields)
+}
+
+func InsertHoliday(client *mongo.Client, dbName, collName string, holiday *Holiday) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if holiday.DateTo.Before(holiday.DateFrom) {
+		return ErrInvalidDateRange
+	}
+	collection := client.Database(dbName).Collection(collName)
+	_, err := collection.InsertOne(ctx, holiday)
+	return err
+}
+
+func UpdateHoliday(client *mongo.Client, dbName, collName string, holiday *Holiday) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if holiday.DateTo.Before(holiday.DateFrom) {
+		return ErrInvalidDateRange
+	}
+	collection := client.Database(dbName).Collection(collName)
+	res, err := collection.UpdateOne(ctx, activeFilter(bson.M{"_id": holiday.ID}, false), bson.M{"$set": bson.M{
+		"name":      holiday.Name,
+		"date_from": holiday.DateFrom,
+		"date_to":   holiday.DateTo,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func DeleteHoliday(client *mongo.Client, dbName, collName string, id primitive.ObjectID, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	return softDeleteOne(ctx, collection, bson.M{"_id": id}, deletedBy)
+}
+
+// UpsertHolidayByUID inserts or updates the holiday imported from the ICS event holiday.UID
+func UpsertHolidayByUID(client *mongo.Client, dbName, collName string, holiday *Holiday) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	return upsertByUID(ctx, collection, bson.M{"ics_uid": holiday.UID}, bson.M{
+		"name":      holiday.Name,
+		"date_from": holiday.DateFrom,
+		"date_to":   holiday.DateTo,
+	})
+}
+
+// upsertByUID updates the record matching key, reviving it if deleted, or inserts it.
+// Returns true when a record was inserted.
+func upsertByUID(ctx context.Context, collection *mongo.Collection, key, set bson.M) (bool, error) {
+	res, err := collection.UpdateOne(ctx, key, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount > 0, nil
+}
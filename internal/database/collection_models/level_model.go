@@ -13,6 +13,8 @@ type Level struct {
 	ID         primitive.ObjectID `bson:"_id,omitempty"`
 	Team       string             `bson:"team"`
 	LevelPoint []int              `bson:"levelPoint"`
+
+	SoftDelete `bson:",inline"`
 }
 
 // Add to the databse a new level for a team
@@ -20,8 +22,7 @@ func AddNewLevelForTeam(client *mongo.Client, dbName, collectionName string, lev
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.InsertOne(ctx, level)
-	return err
+	return insertOrReplaceDeleted(ctx, collection, bson.M{"team": level.Team}, level)
 }
 
 // Update the level points for a team
@@ -30,7 +31,7 @@ func UpdateLevelPointsForTeam(client *mongo.Client, dbName, collectionName strin
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
 	_, err := collection.UpdateOne(ctx,
-		bson.M{"team": level.Team},
+		activeFilter(bson.M{"team": level.Team}, false),
 		bson.M{"$set": bson.M{"levelPoint": level.LevelPoint}},
 	)
 	return err
@@ -42,26 +43,33 @@ func GetLevelPointsForTeam(client *mongo.Client, dbName, collectionName, team st
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
 	var level Level
-	err := collection.FindOne(ctx, bson.M{"team": team}).Decode(&level)
+	err := collection.FindOne(ctx, activeFilter(bson.M{"team": team}, false)).Decode(&level)
 	if err != nil {
 		return nil, err
 	}
 	return &level, nil
 }
 
-func DeleteLevelForTeam(client *mongo.Client, dbName, collectionName, team string) error {
+func DeleteLevelForTeam(client *mongo.Client, dbName, collectionName, team, deletedBy string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.DeleteOne(ctx, bson.M{"team": team})
-	return err
+	return softDeleteOne(ctx, collection, bson.M{"team": team}, deletedBy)
+}
+
+func RestoreLevelForTeam(client *mongo.Client, dbName, collectionName, team string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	return restoreOne(ctx, collection, bson.M{"team": team})
 }
 
-func GetAllLevels(client *mongo.Client, dbName, collectionName string) ([]Level, error) {
+// GetAllLevels returns the level points used for scoring, deleted ones only when includeDeleted is set
+func GetAllLevels(client *mongo.Client, dbName, collectionName string, includeDeleted bool) ([]Level, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	cursor, err := collection.Find(ctx, bson.M{})
+	cursor, err := collection.Find(ctx, activeFilter(bson.M{}, includeDeleted))
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +84,7 @@ func GetAllLevelByTeam(client *mongo.Client, dbName, collectionName, team string
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	cursor, err := collection.Find(ctx, bson.M{"team": team})
+	cursor, err := collection.Find(ctx, activeFilter(bson.M{"team": team}, false))
 	if err != nil {
 		return nil, err
 	}
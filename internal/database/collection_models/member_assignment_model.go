@@ -0,0 +1,307 @@
+package collectionmodels
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrInvalidTransferDate = errors.New("transfer date must be after the start of the current assignment")
+	ErrMembershipExists    = errors.New("member already belongs to this team")
+	ErrMembershipNotFound  = errors.New("member has no active membership in this team")
+)
+
+// TeamAssignment is a team membership and the role held in it over [From, To).
+// A zero From means since the beginning, a nil To means still ongoing.
+type TeamAssignment struct {
+	Team string     `bson:"team"`
+	Role string     `bson:"role"`
+	From time.Time  `bson:"from"`
+	To   *time.Time `bson:"to,omitempty"`
+}
+
+func (a TeamAssignment) ActiveAt(t time.Time) bool {
+	return !t.Before(a.From) && (a.To == nil || t.Before(*a.To))
+}
+
+// MembershipsAt returns the memberships valid at t
+func (m *Member) MembershipsAt(t time.Time) []TeamAssignment {
+	var res []TeamAssignment
+	for _, a := range m.Assignments {
+		if a.ActiveAt(t) {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// TeamsAt returns the teams the member belonged to at t
+func (m *Member) TeamsAt(t time.Time) []string {
+	var teams []string
+	for _, a := range m.MembershipsAt(t) {
+		if !containsString(teams, a.Team) {
+			teams = append(teams, a.Team)
+		}
+	}
+	return teams
+}
+
+// ActiveMembershipFilter matches members whose assignments include team at time t
+func ActiveMembershipFilter(team string, t time.Time) bson.M {
+	return bson.M{"$elemMatch": bson.M{
+		"team": team,
+		"from": bson.M{"$lte": t},
+		"$or": bson.A{
+			bson.M{"to": nil},
+			bson.M{"to": bson.M{"$gt": t}},
+		},
+	}}
+}
+
+// closeAssignments ends the ongoing assignments accepted by match at t, dropping those that would start after it
+func closeAssignments(assignments []TeamAssignment, t time.Time) []TeamAssignment {
+	return closeMatchingAssignments(assignments, t, func(TeamAssignment) bool { return true })
+}
+
+func closeMatchingAssignments(assignments []TeamAssignment, t time.Time, match func(TeamAssignment) bool) []TeamAssignment {
+	var res []TeamAssignment
+	for _, a := range assignments {
+		if match(a) && (a.To == nil || a.To.After(t)) {
+			if !a.From.Before(t) {
+				continue
+			}
+			end := t
+			a.To = &end
+		}
+		res = append(res, a)
+	}
+	return res
+}
+
+// restoredAssignments closes the assignments a deleted member still had open at their deletion, then adds the new
+// ones. A new assignment continuing a past one of the same team and role extends it instead of duplicating it.
+func restoredAssignments(past []TeamAssignment, deletedAt time.Time, added []TeamAssignment) []TeamAssignment {
+	res := closeAssignments(past, deletedAt)
+	for _, a := range added {
+		extended := false
+		for i := range res {
+			p := &res[i]
+			if p.Team == a.Team && p.Role == a.Role && !p.From.After(a.From) && (p.To == nil || !p.To.Before(a.From)) {
+				p.To = a.To
+				extended = true
+				break
+			}
+		}
+		if !extended {
+			res = append(closeMatchingAssignments(res, a.From, inTeam(a.Team)), a)
+		}
+	}
+	if res == nil {
+		res = []TeamAssignment{}
+	}
+	return res
+}
+
+func inTeam(team string) func(TeamAssignment) bool {
+	return func(a TeamAssignment) bool { return a.Team == team }
+}
+
+// updateAssignments loads an active member, applies change to their assignments and saves the result
+func updateAssignments(client *mongo.Client, dbName, collName, memberID string, change func([]TeamAssignment) ([]TeamAssignment, error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+
+	filter := activeFilter(bson.M{"id": memberID}, false)
+	var member Member
+	if err := collection.FindOne(ctx, filter).Decode(&member); err != nil {
+		return err
+	}
+	assignments, err := change(member.Assignments)
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"assignments": assignments}})
+	return err
+}
+
+// AddMembership adds the member to a team with the given role from the given date on
+func AddMembership(client *mongo.Client, dbName, collName, memberID, team, role string, from time.Time) error {
+	return updateAssignments(client, dbName, collName, memberID, func(assignments []TeamAssignment) ([]TeamAssignment, error) {
+		for _, a := range assignments {
+			if a.Team == team && (a.To == nil || a.To.After(from)) {
+				return nil, ErrMembershipExists
+			}
+		}
+		return append(assignments, TeamAssignment{Team: team, Role: role, From: from}), nil
+	})
+}
+
+// RemoveMembership ends the member's membership of a team at the given date, keeping it in the history
+func RemoveMembership(client *mongo.Client, dbName, collName, memberID, team string, at time.Time) error {
+	return updateAssignments(client, dbName, collName, memberID, func(assignments []TeamAssignment) ([]TeamAssignment, error) {
+		found := false
+		for _, a := range assignments {
+			if a.Team == team && a.To == nil {
+				found = true
+			}
+		}
+		if !found {
+			return nil, ErrMembershipNotFound
+		}
+		return closeMatchingAssignments(assignments, at, inTeam(team)), nil
+	})
+}
+
+// TransferMember moves a member from one of their teams to a new team and role from the effective date on
+func TransferMember(client *mongo.Client, dbName, collName, memberID, fromTeam, team, role string, effective time.Time) error {
+	return updateAssignments(client, dbName, collName, memberID, func(assignments []TeamAssignment) ([]TeamAssignment, error) {
+		found := false
+		for _, a := range assignments {
+			if a.Team != fromTeam || a.To != nil {
+				continue
+			}
+			if !a.From.Before(effective) {
+				return nil, ErrInvalidTransferDate
+			}
+			found = true
+		}
+		if !found {
+			return nil, ErrMembershipNotFound
+		}
+		res := closeMatchingAssignments(assignments, effective, inTeam(fromTeam))
+		return append(res, TeamAssignment{Team: team, Role: role, From: effective}), nil
+	})
+}
+
+// GetMemberHistory returns a member with their full assignment history, deleted members included
+func GetMemberHistory(client *mongo.Client, dbName, collName, memberID string) (*Member, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	var member Member
+	if err := collection.FindOne(ctx, bson.M{"id": memberID}).Decode(&member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// TeamAttribution credits completed tasks to teams, deleted members until their deletion. Tasks of known members count for the team stored on the task
+// when they were assigned to it on the task's done date, else for every team they were assigned to that day.
+// Tasks of assignees without a member record fall back to the team stored on the task.
+type TeamAttribution struct {
+	members map[string]*Member
+}
+
+// LoadTeamAttribution loads the assignments of every member once, to attribute any number of tasks
+func LoadTeamAttribution(client *mongo.Client, dbName, memberCollName string) (*TeamAttribution, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(memberCollName)
+
+	cursor, err := collection.Find(ctx, bson.M{"assignments.0": bson.M{"$exists": true}})
+	if err != nil {
+		return nil, err
+	}
+	var members []Member
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, err
+	}
+	attribution := &TeamAttribution{members: make(map[string]*Member, len(members))}
+	for i := range members {
+		// Members deleted before their assignments were closed on deletion left them open
+		if members[i].DeletedAt != nil {
+			members[i].Assignments = closeAssignments(members[i].Assignments, *members[i].DeletedAt)
+		}
+		attribution.members[members[i].Email] = &members[i]
+	}
+	return attribution, nil
+}
+
+// Emails returns the members with assignments
+func (t *TeamAttribution) Emails() []string {
+	emails := make([]string, 0, len(t.members))
+	for email := range t.members {
+		emails = append(emails, email)
+	}
+	return emails
+}
+
+// Teams returns every team a member was ever assigned to
+func (t *TeamAttribution) Teams() []string {
+	var teams []string
+	for _, m := range t.members {
+		for _, a := range m.Assignments {
+			if !containsString(teams, a.Team) {
+				teams = append(teams, a.Team)
+			}
+		}
+	}
+	return teams
+}
+
+// MembersOf returns the members assigned to team at some point of [startDate, endDate]
+func (t *TeamAttribution) MembersOf(team string, startDate, endDate time.Time) []string {
+	var emails []string
+	for email, m := range t.members {
+		for _, a := range m.Assignments {
+			if a.Team == team && !a.From.After(endDate) && (a.To == nil || a.To.After(startDate)) {
+				emails = append(emails, email)
+				break
+			}
+		}
+	}
+	return emails
+}
+
+// TaskFilter builds the completed task filter of the given teams over [startDate, endDate].
+// It also matches tasks a member did for another of their teams, TeamsOf tells which teams each task counts for.
+func (t *TeamAttribution) TaskFilter(teams []string, startDate, endDate time.Time) bson.M {
+	// A nil slice would be encoded as null, which $nin rejects
+	knownEmails := []string{}
+	clauses := bson.A{}
+	for email, m := range t.members {
+		knownEmails = append(knownEmails, email)
+		for _, a := range m.Assignments {
+			if !containsString(teams, a.Team) {
+				continue
+			}
+			if (a.To != nil && !a.To.After(startDate)) || a.From.After(endDate) {
+				continue
+			}
+			doneDate := bson.M{"$gte": startDate, "$lte": endDate}
+			if a.From.After(startDate) {
+				doneDate["$gte"] = a.From
+			}
+			if a.To != nil && !a.To.After(endDate) {
+				delete(doneDate, "$lte")
+				doneDate["$lt"] = *a.To
+			}
+			clauses = append(clauses, bson.M{"assignee_id": email, "done_date": doneDate})
+		}
+	}
+	clauses = append(clauses, bson.M{
+		"team":        bson.M{"$in": teams},
+		"assignee_id": bson.M{"$nin": knownEmails},
+		"done_date":   bson.M{"$gte": startDate, "$lte": endDate},
+	})
+	return bson.M{"$or": clauses}
+}
+
+// TeamsOf returns the teams the task counts for. A member of several teams is credited only for the team the task
+// was done for, or for all their teams when it is none of them.
+func (t *TeamAttribution) TeamsOf(task *CompletedTask) []string {
+	m, ok := t.members[task.AssigneeID]
+	if !ok {
+		return []string{task.Team}
+	}
+	teams := m.TeamsAt(task.DoneDate)
+	if containsString(teams, task.Team) {
+		return []string{task.Team}
+	}
+	return teams
+}
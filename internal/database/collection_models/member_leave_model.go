@@ -0,0 +1,118 @@
+package collectionmodels
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MemberLeave is a leave of one member from DateFrom to DateTo, both days included.
+// UID is the ICS event it was imported from, if any.
+type MemberLeave struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty"`
+	Email    string             `bson:"email"`
+	Reason   string             `bson:"reason"`
+	DateFrom time.Time          `bson:"date_from"`
+	DateTo   time.Time          `bson:"date_to"`
+	UID      string             `bson:"ics_uid,omitempty"`
+
+	SoftDelete `bson:",inline"`
+}
+
+// GetMemberLeaves returns the leaves of the given members overlapping [dateFrom, dateTo]
+func GetMemberLeaves(client *mongo.Client, dbName, collName string, emails []string, dateFrom, dateTo time.Time) ([]MemberLeave, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	filter := bson.M{
+		"email":     bson.M{"$in": emails},
+		"date_from": bson.M{"$lte": dateTo},
+		"date_to":   bson.M{"$gte": dateFrom},
+	}
+	cursor, err := collection.Find(ctx, activeFilter(filter, false))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var leaves []MemberLeave
+	if err = cursor.All(ctx, &leaves); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+var memberLeaveSortFields = []string{"email", "date_from", "date_to"}
+
+// GetMemberLeavesPage returns one page of leaves matching the spec, plus the cursor of the next page.
+// The start week range keeps every leave overlapping it.
+func GetMemberLeavesPage(client *mongo.Client, dbName, collName string, spec QuerySpec) ([]MemberLeave, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	filter := bson.M{}
+	if spec.Member != "" {
+		filter["email"] = spec.Member
+	}
+	if spec.StartWeekFrom != nil {
+		filter["date_to"] = bson.M{"$gte": *spec.StartWeekFrom}
+	}
+	if spec.StartWeekTo != nil {
+		filter["date_from"] = bson.M{"$lte": *spec.StartWeekTo}
+	}
+	return findPage[MemberLeave](ctx, collection, activeFilter(filter, spec.IncludeDeleted), spec, memberLeaveSortFields)
+}
+
+func InsertMemberLeave(client *mongo.Client, dbName, collName string, leave *MemberLeave) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if leave.DateTo.Before(leave.DateFrom) {
+		return ErrInvalidDateRange
+	}
+	collection := client.Database(dbName).Collection(collName)
+	_, err := collection.InsertOne(ctx, leave)
+	return err
+}
+
+func UpdateMemberLeave(client *mongo.Client, dbName, collName string, leave *MemberLeave) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if leave.DateTo.Before(leave.DateFrom) {
+		return ErrInvalidDateRange
+	}
+	collection := client.Database(dbName).Collection(collName)
+	res, err := collection.UpdateOne(ctx, activeFilter(bson.M{"_id": leave.ID}, false), bson.M{"$set": bson.M{
+		"email":     leave.Email,
+		"reason":    leave.Reason,
+		"date_from": leave.DateFrom,
+		"date_to":   leave.DateTo,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func DeleteMemberLeave(client *mongo.Client, dbName, collName string, id primitive.ObjectID, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	return softDeleteOne(ctx, collection, bson.M{"_id": id}, deletedBy)
+}
+
+// UpsertMemberLeaveByUID inserts or updates the leave of leave.Email imported from the ICS event leave.UID
+func UpsertMemberLeaveByUID(client *mongo.Client, dbName, collName string, leave *MemberLeave) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	return upsertByUID(ctx, collection, bson.M{"email": leave.Email, "ics_uid": leave.UID}, bson.M{
+		"reason":    leave.Reason,
+		"date_from": leave.DateFrom,
+		"date_to":   leave.DateTo,
+	})
+}
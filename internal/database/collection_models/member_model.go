@@ -2,60 +2,160 @@ package collectionmodels
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// Member is one person, identified by email. The teams and roles they hold are
+// the active entries of Assignments, a lead can be member of several teams at once.
 type Member struct {
 	ID       primitive.ObjectID `bson:"_id,omitempty"`
 	MemberID string             `bson:"id"`
 	Name     string             `bson:"name"`
 	YOB      int                `bson:"yob"`
 	Email    string             `bson:"email"`
-	Role     string             `bson:"role"`
-	Team     string             `bson:"team"`
+	// WorkPercent is the share of a full time position, 0 means full time
+	WorkPercent int `bson:"work_percent,omitempty"`
+
+	JoinedAt    *time.Time       `bson:"joined_at,omitempty"`
+	LeftAt      *time.Time       `bson:"left_at,omitempty"`
+	Assignments []TeamAssignment `bson:"assignments"`
+
+	SoftDelete `bson:",inline"`
 }
 
-func UpdateMemberToDataBase(client *mongo.Client, url, dbName, collName string, member *Member) error {
+// UpdateMemberToDataBase updates the personal details of a member.
+// Team memberships are changed through AddMembership, RemoveMembership and TransferMember.
+func UpdateMemberToDataBase(client *mongo.Client, dbName, collName string, member *Member) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
 
-	_, err := collection.UpdateOne(
-		ctx,
-		bson.M{"id": member.MemberID},
-		bson.M{"$set": bson.M{
-			"name":  member.Name,
-			"yob":   member.YOB,
-			"email": member.Email,
-			"role":  member.Role,
-			"team":  member.Team,
-		}},
-	)
+	filter := activeFilter(bson.M{"id": member.MemberID}, false)
+	var current Member
+	if err := collection.FindOne(ctx, filter).Decode(&current); err != nil {
+		return err
+	}
+
+	set := bson.M{
+		"name":  member.Name,
+		"yob":   member.YOB,
+		"email": member.Email,
+	}
+	if member.WorkPercent > 0 {
+		set["work_percent"] = member.WorkPercent
+	}
+	if member.JoinedAt != nil {
+		set["joined_at"] = member.JoinedAt
+	}
+	if member.LeftAt != nil {
+		set["left_at"] = member.LeftAt
+		set["assignments"] = closeAssignments(current.Assignments, *member.LeftAt)
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, bson.M{"$set": set})
 	return err
 }
 
-func InsertMemberToDataBase(client *mongo.Client, url, dbName, collName string, member *Member) error {
+// InsertMemberToDataBase adds a member with their initial memberships.
+// A deleted member with the same email is a duplicate: restore them instead, so their history is kept.
+// The restored member keeps their id and past memberships, the new details and memberships are applied on top,
+// a new membership continuing a past one extends it.
+// An active member with the same email fails on the unique email index.
+func InsertMemberToDataBase(client *mongo.Client, dbName, collName string, member *Member) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
 
-	_, err := collection.InsertOne(ctx, member)
+	assignments := member.Assignments
+	if assignments == nil {
+		assignments = []TeamAssignment{}
+	}
+	set := bson.M{
+		"name":  member.Name,
+		"yob":   member.YOB,
+		"email": member.Email,
+	}
+	unset := bson.M{"deleted_at": "", "deleted_by": ""}
+	if member.WorkPercent > 0 {
+		set["work_percent"] = member.WorkPercent
+	} else {
+		unset["work_percent"] = ""
+	}
+	if member.JoinedAt != nil {
+		set["joined_at"] = member.JoinedAt
+	}
+	if member.LeftAt != nil {
+		set["left_at"] = member.LeftAt
+	} else {
+		unset["left_at"] = ""
+	}
+	filter := bson.M{"email": member.Email, "deleted_at": bson.M{"$ne": nil}}
+	var deleted Member
+	err := collection.FindOne(ctx, filter).Decode(&deleted)
+	switch {
+	case err == nil:
+		set["assignments"] = restoredAssignments(deleted.Assignments, *deleted.DeletedAt, assignments)
+		filter["_id"] = deleted.ID
+	case errors.Is(err, mongo.ErrNoDocuments):
+		set["assignments"] = assignments
+	default:
+		return err
+	}
+	update := bson.M{
+		"$set":         set,
+		"$unset":       unset,
+		"$setOnInsert": bson.M{"id": member.MemberID},
+	}
+	_, err = collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
 	return err
 }
 
-func DeleteMemberInDataBase(client *mongo.Client, url, dbName, collName, memberID string) error {
+// DeleteMemberInDataBase soft deletes a member and ends their ongoing memberships, their completed tasks keep
+// pointing to the record
+func DeleteMemberInDataBase(client *mongo.Client, dbName, collName, memberID, deletedBy string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	_, err := collection.DeleteOne(ctx, bson.M{"id": memberID})
-	return err
+
+	filter := activeFilter(bson.M{"id": memberID}, false)
+	var member Member
+	if err := collection.FindOne(ctx, filter).Decode(&member); err != nil {
+		return err
+	}
+	now := time.Now()
+	assignments := closeAssignments(member.Assignments, now)
+	if assignments == nil {
+		assignments = []TeamAssignment{}
+	}
+	res, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{
+		"deleted_at":  now,
+		"deleted_by":  deletedBy,
+		"assignments": assignments,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func RestoreMemberInDataBase(client *mongo.Client, dbName, collName, memberID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	return restoreOne(ctx, collection, bson.M{"id": memberID})
 }
 
-func GetMemberByEmail(client *mongo.Client, url, dbName, collName, email string) (*Member, error) {
+// GetMemberByEmail also returns deleted members, so historical tasks can always be resolved to a name
+func GetMemberByEmail(client *mongo.Client, dbName, collName, email string) (*Member, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
@@ -67,11 +167,11 @@ func GetMemberByEmail(client *mongo.Client, url, dbName, collName, email string)
 	return &member, nil
 }
 
-func GetAllMembers(client *mongo.Client, url, dbName, collName string) ([]*Member, error) {
+func GetAllMembers(client *mongo.Client, dbName, collName string) ([]*Member, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	cursor, err := collection.Find(ctx, bson.M{})
+	cursor, err := collection.Find(ctx, activeFilter(bson.M{}, false))
 	if err != nil {
 		return nil, err
 	}
@@ -82,3 +182,29 @@ func GetAllMembers(client *mongo.Client, url, dbName, collName string) ([]*Membe
 	}
 	return members, nil
 }
+
+var memberSortFields = []string{"id", "name", "yob", "email"}
+
+// GetMembersPage returns one page of members matching the spec, plus the cursor of the next page.
+// The project filter keeps the members currently assigned to the project of that name or alias.
+func GetMembersPage(client *mongo.Client, dbName string, collections Collections, spec QuerySpec) ([]*Member, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	database := client.Database(dbName)
+	filter := bson.M{}
+	if spec.Team != "" {
+		filter["assignments"] = ActiveMembershipFilter(spec.Team, time.Now())
+	}
+	if spec.Member != "" {
+		filter["email"] = spec.Member
+	}
+	if spec.Project != "" {
+		emails, err := projectMemberEmails(ctx, database.Collection(collections.ProjectDetail), spec.Project, time.Now())
+		if err != nil {
+			return nil, "", err
+		}
+		filter["$and"] = bson.A{bson.M{"email": bson.M{"$in": emails}}}
+	}
+	collection := database.Collection(collections.StaffMember)
+	return findPage[*Member](ctx, collection, activeFilter(filter, spec.IncludeDeleted), spec, memberSortFields)
+}
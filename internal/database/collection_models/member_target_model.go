@@ -0,0 +1,119 @@
+package collectionmodels
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MemberTarget is an explicit weekly target of one member. In its date range it replaces
+// the member's share of their team targets.
+type MemberTarget struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty"`
+	Email    string             `bson:"email"`
+	Point    int                `bson:"point"`
+	DateFrom time.Time          `bson:"date_from"`
+	DateTo   time.Time          `bson:"date_to"`
+
+	SoftDelete `bson:",inline"`
+}
+
+// GetMemberTargets returns the targets of the given members overlapping [dateFrom, dateTo]
+func GetMemberTargets(client *mongo.Client, dbName, collName string, emails []string, dateFrom, dateTo time.Time) ([]MemberTarget, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	filter := bson.M{
+		"email":     bson.M{"$in": emails},
+		"date_from": bson.M{"$lte": dateTo},
+		"date_to":   bson.M{"$gte": dateFrom},
+	}
+	cursor, err := collection.Find(ctx, activeFilter(filter, false))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var targets []MemberTarget
+	if err = cursor.All(ctx, &targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+var memberTargetSortFields = []string{"email", "point", "date_from", "date_to"}
+
+// GetMemberTargetsPage returns one page of member targets matching the spec, plus the cursor of the next page.
+// The start week range keeps every target whose date range overlaps it.
+func GetMemberTargetsPage(client *mongo.Client, dbName, collName string, spec QuerySpec) ([]MemberTarget, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	filter := bson.M{}
+	if spec.Member != "" {
+		filter["email"] = spec.Member
+	}
+	if spec.StartWeekFrom != nil {
+		filter["date_to"] = bson.M{"$gte": *spec.StartWeekFrom}
+	}
+	if spec.StartWeekTo != nil {
+		filter["date_from"] = bson.M{"$lte": *spec.StartWeekTo}
+	}
+	return findPage[MemberTarget](ctx, collection, activeFilter(filter, spec.IncludeDeleted), spec, memberTargetSortFields)
+}
+
+// InsertMemberTarget adds a member target, it must not overlap another target of the member
+func InsertMemberTarget(client *mongo.Client, dbName, collName string, target *MemberTarget) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	return writeWithoutOverlap(ctx, collection, "email:"+target.Email, bson.M{"email": target.Email}, target.DateFrom, &target.DateTo, func(ctx context.Context) error {
+		_, err := collection.InsertOne(ctx, target)
+		return err
+	})
+}
+
+// UpdateMemberTarget changes the point and date range of the target with target.ID
+func UpdateMemberTarget(client *mongo.Client, dbName, collName string, target *MemberTarget) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	others := bson.M{"email": target.Email, "_id": bson.M{"$ne": target.ID}}
+	return writeWithoutOverlap(ctx, collection, "email:"+target.Email, others, target.DateFrom, &target.DateTo, func(ctx context.Context) error {
+		res, err := collection.UpdateOne(ctx, activeFilter(bson.M{"_id": target.ID}, false), bson.M{"$set": bson.M{
+			"email":     target.Email,
+			"point":     target.Point,
+			"date_from": target.DateFrom,
+			"date_to":   target.DateTo,
+		}})
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return mongo.ErrNoDocuments
+		}
+		return nil
+	})
+}
+
+func DeleteMemberTarget(client *mongo.Client, dbName, collName string, id primitive.ObjectID, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	return softDeleteOne(ctx, collection, bson.M{"_id": id}, deletedBy)
+}
+
+func RestoreMemberTarget(client *mongo.Client, dbName, collName string, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	var target MemberTarget
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&target); err != nil {
+		return err
+	}
+	return writeWithoutOverlap(ctx, collection, "email:"+target.Email, bson.M{"email": target.Email}, target.DateFrom, &target.DateTo, func(ctx context.Context) error {
+		return restoreOne(ctx, collection, bson.M{"_id": id})
+	})
+}
@@ -0,0 +1,136 @@
+package collectionmodels
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IdentifierKind tells what the identifiers of a performance aggregation are: member emails, team names
+// or project IDs of the catalog, in decimal
+type IdentifierKind string
+
+const (
+	KindMember  IdentifierKind = "member"
+	KindTeam    IdentifierKind = "team"
+	KindProject IdentifierKind = "project"
+)
+
+var ErrInvalidIdentifierKind = errors.New("invalid kind, expected member, team or project")
+
+// ParseIdentifierKind parses kind, an empty kind is KindMember
+func ParseIdentifierKind(kind string) (IdentifierKind, error) {
+	switch k := IdentifierKind(kind); k {
+	case "":
+		return KindMember, nil
+	case KindMember, KindTeam, KindProject:
+		return k, nil
+	}
+	return "", ErrInvalidIdentifierKind
+}
+
+// PerformanceSnapshot is the points of a member, team or project over one whole week, as computed with the scoring
+// config identified by RulesetVersion. Snapshots built with another version are ignored, unless Locked
+// by a closed week: those keep their points whatever changes afterwards.
+type PerformanceSnapshot struct {
+	ID                        primitive.ObjectID `bson:"_id,omitempty"`
+	Identifier                string             `bson:"identifier"`
+	Kind                      IdentifierKind     `bson:"kind"`
+	WeekStart                 time.Time          `bson:"week_start"`
+	WeekEnd                   time.Time          `bson:"week_end"`
+	TotalPerformancePoint     float64            `bson:"total_performance_point"`
+	TotalCreativeProcessPoint float64            `bson:"total_creative_process_point"`
+	TotalCreativeTaskPoint    float64            `bson:"total_creative_task_point"`
+	TotalBasePoint            float64            `bson:"total_base_point"`
+	RulesetVersion            string             `bson:"ruleset_version"`
+	BuiltAt                   time.Time          `bson:"built_at"`
+	Locked                    bool               `bson:"locked,omitempty"`
+}
+
+// GetPerformanceSnapshots returns the snapshots of identifiers built with rulesetVersion or locked, for the weeks starting in [dateFrom, dateTo]
+func GetPerformanceSnapshots(client *mongo.Client, dbName, collectionName string, identifiers []string, kind IdentifierKind, rulesetVersion string, dateFrom, dateTo time.Time) ([]PerformanceSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	cursor, err := collection.Find(ctx, bson.M{
+		"identifier": bson.M{"$in": identifiers},
+		"kind":       kind,
+		"week_start": bson.M{"$gte": dateFrom, "$lte": dateTo},
+		"$or":        bson.A{bson.M{"ruleset_version": rulesetVersion}, bson.M{"locked": true}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var snapshots []PerformanceSnapshot
+	if err = cursor.All(ctx, &snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// SavePerformanceSnapshots inserts or replaces the snapshot of each identifier and week, locked snapshots must not be passed
+func SavePerformanceSnapshots(client *mongo.Client, dbName, collectionName string, snapshots []PerformanceSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	models := make([]mongo.WriteModel, len(snapshots))
+	for i, s := range snapshots {
+		models[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"identifier": s.Identifier, "kind": s.Kind, "week_start": s.WeekStart}).
+			SetReplacement(s).
+			SetUpsert(true)
+	}
+	_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// DeletePerformanceSnapshots removes the unlocked snapshots of the weeks starting in [dateFrom, dateTo], a nil dateTo is open ended.
+// A kind only removes the snapshots of that kind, for changes such as moving tasks between teams that leave the others right.
+func DeletePerformanceSnapshots(client *mongo.Client, dbName, collectionName string, dateFrom time.Time, dateTo *time.Time, kind IdentifierKind) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	weekStart := bson.M{"$gte": dateFrom}
+	if dateTo != nil {
+		weekStart["$lte"] = *dateTo
+	}
+	filter := bson.M{"week_start": weekStart, "locked": bson.M{"$ne": true}}
+	if kind != "" {
+		filter["kind"] = kind
+	}
+	res, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// LockPerformanceSnapshots locks the snapshots of identifiers for the week starting at weekStart
+func LockPerformanceSnapshots(client *mongo.Client, dbName, collectionName string, identifiers []string, kind IdentifierKind, weekStart time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"identifier": bson.M{"$in": identifiers}, "kind": kind, "week_start": weekStart},
+		bson.M{"$set": bson.M{"locked": true}})
+	return err
+}
+
+// ReleasePerformanceSnapshots removes the snapshots of identifiers for the week starting at weekStart, locked or not,
+// so that they are computed again from the current tasks
+func ReleasePerformanceSnapshots(client *mongo.Client, dbName, collectionName string, identifiers []string, kind IdentifierKind, weekStart time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.DeleteMany(ctx, bson.M{"identifier": bson.M{"$in": identifiers}, "kind": kind, "week_start": weekStart})
+	return err
+}
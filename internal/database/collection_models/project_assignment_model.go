@@ -0,0 +1,155 @@
+package collectionmodels
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrProjectAssignmentExists   = errors.New("member is already assigned to this project for this team")
+	ErrProjectAssignmentNotFound = errors.New("member has no ongoing assignment to this project for this team")
+	ErrProjectAssignmentConflict = errors.New("project assignments were changed concurrently, try again")
+)
+
+// ProjectAssignment staffs a project with a member, by email, for one of the teams it orders from over [From, To).
+// A zero From means since the beginning, a nil To means still ongoing.
+type ProjectAssignment struct {
+	Email string     `bson:"email"`
+	Team  string     `bson:"team"`
+	Role  string     `bson:"role"`
+	From  time.Time  `bson:"from"`
+	To    *time.Time `bson:"to,omitempty"`
+}
+
+// ProjectRoleOwner is the default role of a project assignment
+const ProjectRoleOwner = "owner"
+
+// Overlaps reports whether the assignment is active at some point of [startDate, endDate]
+func (a ProjectAssignment) Overlaps(startDate, endDate time.Time) bool {
+	return !a.From.After(endDate) && (a.To == nil || a.To.After(startDate))
+}
+
+// AssignmentsOf returns the assignments of team active at some point of [startDate, endDate], of every team when
+// team is empty
+func (p *ProjectDetail) AssignmentsOf(team string, startDate, endDate time.Time) []ProjectAssignment {
+	res := []ProjectAssignment{}
+	for _, a := range p.Assignments {
+		if (team == "" || a.Team == team) && a.Overlaps(startDate, endDate) {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// projectAssignmentAttempts bounds how many times a change is applied again after a concurrent one
+const projectAssignmentAttempts = 3
+
+// updateProjectAssignments loads an active project, applies change to its assignments and saves the result.
+// The save only goes through while the assignments are still the ones read, byte for byte, so a concurrent
+// change is never overwritten: change is then applied again to the new assignments.
+func updateProjectAssignments(client *mongo.Client, dbName, collName string, projectID int, change func([]ProjectAssignment) ([]ProjectAssignment, error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+
+	filter := activeFilter(bson.M{"id": projectID}, false)
+	for attempt := 0; attempt < projectAssignmentAttempts; attempt++ {
+		raw, err := collection.FindOne(ctx, filter).Raw()
+		if err != nil {
+			return err
+		}
+		var detail ProjectDetail
+		if err := bson.Unmarshal(raw, &detail); err != nil {
+			return err
+		}
+		assignments, err := change(detail.Assignments)
+		if err != nil {
+			return err
+		}
+
+		unchanged := bson.M{"_id": detail.ID, "assignments": bson.M{"$exists": false}}
+		if read, err := raw.LookupErr("assignments"); err == nil {
+			unchanged["assignments"] = read
+		}
+		res, err := collection.UpdateOne(ctx, unchanged, bson.M{"$set": bson.M{"assignments": assignments}})
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount > 0 {
+			return nil
+		}
+	}
+	return ErrProjectAssignmentConflict
+}
+
+// AddProjectAssignment assigns a member to a project for a team from assignment.From on
+func AddProjectAssignment(client *mongo.Client, dbName, collName string, projectID int, assignment ProjectAssignment) error {
+	return updateProjectAssignments(client, dbName, collName, projectID, func(assignments []ProjectAssignment) ([]ProjectAssignment, error) {
+		for _, a := range assignments {
+			if a.Email == assignment.Email && a.Team == assignment.Team && (a.To == nil || a.To.After(assignment.From)) {
+				return nil, ErrProjectAssignmentExists
+			}
+		}
+		return append(assignments, assignment), nil
+	})
+}
+
+// EndProjectAssignment ends the ongoing assignment of a member to a project for a team at the given date,
+// keeping it in the history. An assignment that would only start after it is dropped.
+func EndProjectAssignment(client *mongo.Client, dbName, collName string, projectID int, email, team string, at time.Time) error {
+	return updateProjectAssignments(client, dbName, collName, projectID, func(assignments []ProjectAssignment) ([]ProjectAssignment, error) {
+		found := false
+		res := []ProjectAssignment{}
+		for _, a := range assignments {
+			if a.Email == email && a.Team == team && a.To == nil {
+				found = true
+				if !a.From.Before(at) {
+					continue
+				}
+				end := at
+				a.To = &end
+			}
+			res = append(res, a)
+		}
+		if !found {
+			return nil, ErrProjectAssignmentNotFound
+		}
+		return res, nil
+	})
+}
+
+// GetProjectDetail returns a project with its full assignment history, deleted projects included
+func GetProjectDetail(client *mongo.Client, dbName, collName string, projectID int) (*ProjectDetail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	var detail ProjectDetail
+	if err := collection.FindOne(ctx, bson.M{"id": projectID}).Decode(&detail); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+// projectMemberEmails returns the emails of the members assigned at t to the active project going by name,
+// as its name or one of its aliases. An unknown project has no members.
+func projectMemberEmails(ctx context.Context, collection *mongo.Collection, name string, t time.Time) ([]string, error) {
+	filter := activeFilter(bson.M{"$or": bson.A{bson.M{"project": name}, bson.M{"aliases": name}}}, false)
+	var detail ProjectDetail
+	if err := collection.FindOne(ctx, filter).Decode(&detail); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	emails := []string{}
+	for _, a := range detail.AssignmentsOf("", t, t) {
+		if !containsString(emails, a.Email) {
+			emails = append(emails, a.Email)
+		}
+	}
+	return emails, nil
+}
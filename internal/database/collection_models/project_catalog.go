@@ -0,0 +1,161 @@
+package collectionmodels
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrUnknownProject   = errors.New("unknown project")
+	ErrProjectNameTaken = errors.New("project name or alias already used by another project")
+)
+
+// ProjectCatalog resolves the names a project goes by, its name and its aliases, to its ProjectID.
+// Names are compared ignoring case and extra spaces.
+type ProjectCatalog struct {
+	ids   map[string]int
+	names map[int]string
+}
+
+func normalizeProjectName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// LoadProjectCatalog loads every project once, deleted ones included so their history still resolves
+func LoadProjectCatalog(client *mongo.Client, dbName, collName string) (*ProjectCatalog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	cursor, err := collection.Find(ctx, bson.M{"id": bson.M{"$gt": 0}})
+	if err != nil {
+		return nil, err
+	}
+	var projects []ProjectDetail
+	if err := cursor.All(ctx, &projects); err != nil {
+		return nil, err
+	}
+	return NewProjectCatalog(projects), nil
+}
+
+// NewProjectCatalog indexes the names and aliases of projects
+func NewProjectCatalog(projects []ProjectDetail) *ProjectCatalog {
+	catalog := &ProjectCatalog{ids: map[string]int{}, names: map[int]string{}}
+	for _, p := range projects {
+		catalog.names[p.ProjectID] = p.Project
+		for _, alias := range p.Aliases {
+			catalog.ids[normalizeProjectName(alias)] = p.ProjectID
+		}
+	}
+	// A project name wins over an alias of another project
+	for _, p := range projects {
+		catalog.ids[normalizeProjectName(p.Project)] = p.ProjectID
+	}
+	return catalog
+}
+
+// Resolve returns the ID of the project going by name
+func (c *ProjectCatalog) Resolve(name string) (int, bool) {
+	id, ok := c.ids[normalizeProjectName(name)]
+	return id, ok
+}
+
+// Name returns the canonical name of a project
+func (c *ProjectCatalog) Name(id int) string {
+	return c.names[id]
+}
+
+// IDs returns the ID of every project, sorted
+func (c *ProjectCatalog) IDs() []int {
+	ids := make([]int, 0, len(c.names))
+	for id := range c.names {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Reserve gives name the ID following the highest one known, so that later lookups of name resolve to it
+func (c *ProjectCatalog) Reserve(name string) int {
+	id := 1
+	for known := range c.names {
+		id = max(id, known+1)
+	}
+	c.Register(id, name)
+	return id
+}
+
+// Register makes name resolve to the project id
+func (c *ProjectCatalog) Register(id int, name string) {
+	if _, ok := c.names[id]; !ok {
+		c.names[id] = name
+	}
+	c.ids[normalizeProjectName(name)] = id
+}
+
+// available reports whether names can be given to project id without clashing with another project
+func (c *ProjectCatalog) available(id int, names ...string) bool {
+	for _, name := range names {
+		if other, ok := c.Resolve(name); ok && other != id {
+			return false
+		}
+	}
+	return true
+}
+
+// nextProjectID returns the ID following the highest one in use
+func nextProjectID(ctx context.Context, collection *mongo.Collection) (int, error) {
+	var last ProjectDetail
+	err := collection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return last.ProjectID + 1, nil
+}
+
+// AddProjectAlias lets the project be found by another name, such as a former name or a spelling used in Asana
+func AddProjectAlias(client *mongo.Client, dbName, collName string, projectID int, alias string) error {
+	alias = strings.TrimSpace(alias)
+	catalog, err := LoadProjectCatalog(client, dbName, collName)
+	if err != nil {
+		return err
+	}
+	if !catalog.available(projectID, alias) {
+		return ErrProjectNameTaken
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	res, err := collection.UpdateOne(ctx, activeFilter(bson.M{"id": projectID}, false), bson.M{"$addToSet": bson.M{"aliases": alias}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// RemoveProjectAlias stops resolving alias to the project, tasks already resolved keep their project
+func RemoveProjectAlias(client *mongo.Client, dbName, collName string, projectID int, alias string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	res, err := collection.UpdateOne(ctx, activeFilter(bson.M{"id": projectID}, false), bson.M{"$pull": bson.M{"aliases": strings.TrimSpace(alias)}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
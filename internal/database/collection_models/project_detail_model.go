@@ -9,48 +9,96 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// ProjectDetail is a project of the catalog and its staffing. ProjectID is the stable key orders and tasks refer to,
+// Project is the current name and Aliases the other names it goes by, in Asana or before a rename.
+// Who works on it for each team is the history of Assignments, a team can staff a project with several members at once.
 type ProjectDetail struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	ProjectID int                `bson:"id"`
-	Project   string             `bson:"project"`
-	Research  string             `bson:"research"`
-	Art       string             `bson:"art"`
-	Concept   string             `bson:"concept"`
-	Video     string             `bson:"video"`
-	Pla       string             `bson:"pla"`
-	UA        string             `bson:"ua"`
+	ID          primitive.ObjectID  `bson:"_id,omitempty"`
+	ProjectID   int                 `bson:"id"`
+	Project     string              `bson:"project"`
+	Aliases     []string            `bson:"aliases"`
+	Assignments []ProjectAssignment `bson:"assignments"`
+
+	SoftDelete `bson:",inline"`
 }
 
+// InstertNewProjectDetailToDatabase adds a project to the catalog. A zero ProjectID is given the next free ID.
 func InstertNewProjectDetailToDatabase(client *mongo.Client, dbName, collName string, projectDetail *ProjectDetail) error {
+	catalog, err := LoadProjectCatalog(client, dbName, collName)
+	if err != nil {
+		return err
+	}
+	if !catalog.available(projectDetail.ProjectID, append([]string{projectDetail.Project}, projectDetail.Aliases...)...) {
+		return ErrProjectNameTaken
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	_, err := collection.InsertOne(ctx, projectDetail)
+	if projectDetail.ProjectID == 0 {
+		if projectDetail.ProjectID, err = nextProjectID(ctx, collection); err != nil {
+			return err
+		}
+	}
+	if projectDetail.Aliases == nil {
+		projectDetail.Aliases = []string{}
+	}
+	if projectDetail.Assignments == nil {
+		projectDetail.Assignments = []ProjectAssignment{}
+	}
+	_, err = collection.InsertOne(ctx, projectDetail)
 	return err
 }
 
+// UpdateProjectDetailToDatabase renames the project of projectDetail.ProjectID, the former name is kept as an alias.
+// Its staffing is changed through AddProjectAssignment and EndProjectAssignment.
 func UpdateProjectDetailToDatabase(client *mongo.Client, dbName, collName string, projectDetail *ProjectDetail) error {
+	catalog, err := LoadProjectCatalog(client, dbName, collName)
+	if err != nil {
+		return err
+	}
+	if !catalog.available(projectDetail.ProjectID, projectDetail.Project) {
+		return ErrProjectNameTaken
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	_, err := collection.UpdateOne(ctx, bson.M{"project": projectDetail.Project}, bson.M{"$set": projectDetail})
+
+	filter := activeFilter(bson.M{"id": projectDetail.ProjectID}, false)
+	var current ProjectDetail
+	if err := collection.FindOne(ctx, filter).Decode(&current); err != nil {
+		return err
+	}
+	aliases := []string{}
+	for _, alias := range append(current.Aliases, current.Project) {
+		if alias != projectDetail.Project && !containsString(aliases, alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	_, err = collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"project": projectDetail.Project, "aliases": aliases}})
 	return err
 }
 
-func DeleteProjectDetailInDatabase(client *mongo.Client, dbName, collName string, project string) error {
+func DeleteProjectDetailInDatabase(client *mongo.Client, dbName, collName string, project, deletedBy string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
 
-	_, err := collection.DeleteOne(ctx, bson.M{"project": project})
-	return err
+	return softDeleteOne(ctx, collection, bson.M{"project": project}, deletedBy)
+}
+
+func RestoreProjectDetailInDatabase(client *mongo.Client, dbName, collName string, project string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+
+	return restoreOne(ctx, collection, bson.M{"project": project})
 }
 
 func GetAllProjectDetails(client *mongo.Client, dbName, collName string) ([]ProjectDetail, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	cursor, err := collection.Find(ctx, bson.M{})
+	cursor, err := collection.Find(ctx, activeFilter(bson.M{}, false))
 	if err != nil {
 		return nil, err
 	}
@@ -66,3 +114,23 @@ func GetAllProjectDetails(client *mongo.Client, dbName, collName string) ([]Proj
 	}
 	return results, nil
 }
+
+var projectDetailSortFields = []string{"id", "project"}
+
+// GetProjectDetailsPage returns one page of project details matching the spec, plus the cursor of the next page
+func GetProjectDetailsPage(client *mongo.Client, dbName, collName string, spec QuerySpec) ([]ProjectDetail, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	filter := bson.M{}
+	if spec.Project != "" {
+		filter["$or"] = bson.A{bson.M{"project": spec.Project}, bson.M{"aliases": spec.Project}}
+	}
+	if spec.Member != "" {
+		filter["assignments.email"] = spec.Member
+	}
+	if spec.Team != "" {
+		filter["assignments.team"] = spec.Team
+	}
+	return findPage[ProjectDetail](ctx, collection, activeFilter(filter, spec.IncludeDeleted), spec, projectDetailSortFields)
+}
@@ -11,6 +11,7 @@ import (
 
 type ProjectIssue struct {
 	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	ProjectID      int                `bson:"project_id"`
 	Project        string             `bson:"project"`
 	StartWeek      time.Time          `bson:"start_week"`
 	CompletedCount int                `bson:"completed_count"`
@@ -20,10 +21,10 @@ type ProjectIssue struct {
 	OrderCount     int                `bson:"order_count"`
 }
 
-func GetProjectIssues(client *mongo.Client, dbName, collectionName string, startTime, endTime time.Time) (*[]ProjectIssue, error) {
+func GetProjectIssues(client *mongo.Client, dbName string, collections Collections, startTime, endTime time.Time) (*[]ProjectIssue, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	collection := client.Database(dbName).Collection(collectionName)
+	collection := client.Database(dbName).Collection(collections.WeeklyOrder)
 
 	pipeline := mongo.Pipeline{
 		// 1. Filter weekly orders by date range (ví dụ tháng 9/2025)
@@ -32,24 +33,34 @@ func GetProjectIssues(client *mongo.Client, dbName, collectionName string, start
 				{Key: "$gte", Value: startTime},
 				{Key: "$lte", Value: endTime},
 			}},
+			{Key: "deleted_at", Value: nil},
 		}}},
-		// 2. Build orders
+		// 2. One order per team, summing the quantities of its items
+		{{Key: "$unwind", Value: "$items"}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "project_id", Value: "$project_id"},
+				{Key: "project", Value: "$project"},
+				{Key: "start_week", Value: "$start_week"},
+				{Key: "team", Value: "$items.team"},
+			}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: "$items.quantity"}}},
+		}}},
+		// 3. Build orders
 		{{Key: "$project", Value: bson.D{
-			{Key: "project", Value: 1},
-			{Key: "start_week", Value: 1},
-			{Key: "orders", Value: bson.A{
-				bson.D{{Key: "team", Value: "Art Creative"}, {Key: "count", Value: bson.D{{Key: "$add", Value: bson.A{"$art_cpp", "$art_icon", "$art_banner"}}}}},
-				bson.D{{Key: "team", Value: "Video Creative"}, {Key: "count", Value: "$video"}},
-				bson.D{{Key: "team", Value: "PLA Creative"}, {Key: "count", Value: "$playable"}},
+			{Key: "project_id", Value: "$_id.project_id"},
+			{Key: "project", Value: "$_id.project"},
+			{Key: "start_week", Value: "$_id.start_week"},
+			{Key: "orders", Value: bson.D{
+				{Key: "team", Value: "$_id.team"},
+				{Key: "count", Value: "$count"},
 			}},
 		}}},
-		// 3. Unwind orders
-		{{Key: "$unwind", Value: "$orders"}},
 		// 4. Lookup completed-task (theo project, team, done_date thuộc tuần)
 		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "completed-task"},
+			{Key: "from", Value: collections.CompletedTask},
 			{Key: "let", Value: bson.D{
-				{Key: "proj", Value: "$project"},
+				{Key: "proj", Value: "$project_id"},
 				{Key: "team", Value: "$orders.team"},
 				{Key: "weekStart", Value: "$start_week"},
 			}},
@@ -57,7 +68,7 @@ func GetProjectIssues(client *mongo.Client, dbName, collectionName string, start
 				{{Key: "$match", Value: bson.D{
 					{Key: "$expr", Value: bson.D{
 						{Key: "$and", Value: bson.A{
-							bson.D{{Key: "$eq", Value: bson.A{"$project", "$$proj"}}},
+							bson.D{{Key: "$eq", Value: bson.A{"$project_id", "$$proj"}}},
 							bson.D{{Key: "$eq", Value: bson.A{"$team", "$$team"}}},
 							// Lọc theo tuần
 							bson.D{{Key: "$gte", Value: bson.A{"$done_date", "$$weekStart"}}},
@@ -79,29 +90,42 @@ func GetProjectIssues(client *mongo.Client, dbName, collectionName string, start
 			}},
 			{Key: "as", Value: "completed"},
 		}}},
-		// 5. Lookup fallback project-details
+		// 5. Lookup fallback project-details: the members assigned to the project for the team during the week
 		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "project-details"},
+			{Key: "from", Value: collections.ProjectDetail},
 			{Key: "let", Value: bson.D{
-				{Key: "proj", Value: "$project"},
+				{Key: "proj", Value: "$project_id"},
 				{Key: "team", Value: "$orders.team"},
+				{Key: "weekStart", Value: "$start_week"},
 			}},
 			{Key: "pipeline", Value: mongo.Pipeline{
 				{{Key: "$match", Value: bson.D{
-					{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$project", "$$proj"}}}},
+					{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$id", "$$proj"}}}},
+					{Key: "deleted_at", Value: nil},
 				}}},
-				{{Key: "$project", Value: bson.D{
-					{Key: "assignee", Value: bson.D{
-						{Key: "$switch", Value: bson.D{
-							{Key: "branches", Value: bson.A{
-								bson.D{{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{"$$team", "Art Creative"}}}}, {Key: "then", Value: "$art"}},
-								bson.D{{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{"$$team", "Video Creative"}}}}, {Key: "then", Value: "$video"}},
-								bson.D{{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{"$$team", "PLA Creative"}}}}, {Key: "then", Value: "$pla"}},
-							}},
-							{Key: "default", Value: nil},
+				{{Key: "$unwind", Value: "$assignments"}},
+				{{Key: "$match", Value: bson.D{
+					{Key: "$expr", Value: bson.D{
+						{Key: "$and", Value: bson.A{
+							bson.D{{Key: "$eq", Value: bson.A{"$assignments.team", "$$team"}}},
+							bson.D{{Key: "$lt", Value: bson.A{
+								"$assignments.from",
+								bson.D{{Key: "$dateAdd", Value: bson.D{
+									{Key: "startDate", Value: "$$weekStart"},
+									{Key: "unit", Value: "day"},
+									{Key: "amount", Value: 7},
+								}}},
+							}}},
+							bson.D{{Key: "$or", Value: bson.A{
+								bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$assignments.to", nil}}}, nil}}},
+								bson.D{{Key: "$gt", Value: bson.A{"$assignments.to", "$$weekStart"}}},
+							}}},
 						}},
 					}},
 				}}},
+				{{Key: "$project", Value: bson.D{
+					{Key: "assignee", Value: "$assignments.email"},
+				}}},
 			}},
 			{Key: "as", Value: "fallback"},
 		}}},
@@ -134,6 +158,7 @@ func GetProjectIssues(client *mongo.Client, dbName, collectionName string, start
 		}}},
 		// 9. Final projection
 		{{Key: "$project", Value: bson.D{
+			{Key: "project_id", Value: 1},
 			{Key: "project", Value: 1},
 			{Key: "start_week", Value: 1},
 			{Key: "team", Value: "$orders.team"},
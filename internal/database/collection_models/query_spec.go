@@ -0,0 +1,171 @@
+package collectionmodels
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const MaxPageLimit int64 = 500
+
+var (
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrInvalidSortField = errors.New("invalid sort field")
+)
+
+// QuerySpec describes paging, sorting and filtering for the list endpoints.
+// Limit == 0 returns every matching document and no next cursor.
+type QuerySpec struct {
+	Limit         int64
+	Cursor        string
+	SortField     string
+	SortDesc      bool
+	Team          string
+	Project       string
+	Member        string
+	StartWeekFrom *time.Time
+	StartWeekTo   *time.Time
+
+	IncludeDeleted bool
+}
+
+// pageCursor is the position after the last document of a page, for the sort it was taken with
+type pageCursor struct {
+	SortField string             `bson:"s"`
+	SortDesc  bool               `bson:"d"`
+	Value     bson.RawValue      `bson:"v"`
+	ID        primitive.ObjectID `bson:"id"`
+}
+
+// dateRangeFilter returns a {$gte, $lte} filter for the spec's start week range, or nil when unset
+func (s QuerySpec) dateRangeFilter() bson.M {
+	if s.StartWeekFrom == nil && s.StartWeekTo == nil {
+		return nil
+	}
+	r := bson.M{}
+	if s.StartWeekFrom != nil {
+		r["$gte"] = *s.StartWeekFrom
+	}
+	if s.StartWeekTo != nil {
+		r["$lte"] = *s.StartWeekTo
+	}
+	return r
+}
+
+// findPage runs a keyset-paginated Find. Results are ordered by the sort field then _id,
+// and the returned cursor encodes both values of the last document along with the sort.
+// A cursor taken with another sort field or direction is rejected with ErrInvalidCursor.
+func findPage[T any](ctx context.Context, collection *mongo.Collection, filter bson.M, spec QuerySpec, sortFields []string) ([]T, string, error) {
+	sortField := spec.SortField
+	if sortField == "" {
+		sortField = "_id"
+	}
+	if sortField != "_id" && !containsString(sortFields, sortField) {
+		return nil, "", ErrInvalidSortField
+	}
+
+	direction := 1
+	cmp := "$gt"
+	if spec.SortDesc {
+		direction = -1
+		cmp = "$lt"
+	}
+
+	if spec.Cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(spec.Cursor)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		var c pageCursor
+		if err := bson.Unmarshal(raw, &c); err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		if c.SortField != sortField || c.SortDesc != spec.SortDesc {
+			return nil, "", ErrInvalidCursor
+		}
+		var after bson.M
+		if sortField == "_id" {
+			after = bson.M{"_id": bson.M{cmp: c.ID}}
+		} else {
+			after = bson.M{"$or": bson.A{
+				bson.M{sortField: bson.M{cmp: c.Value}},
+				bson.M{sortField: c.Value, "_id": bson.M{cmp: c.ID}},
+			}}
+		}
+		filter = bson.M{"$and": bson.A{filter, after}}
+	}
+
+	sort := bson.D{{Key: sortField, Value: direction}}
+	if sortField != "_id" {
+		sort = append(sort, bson.E{Key: "_id", Value: direction})
+	}
+	opts := options.Find().SetSort(sort)
+	limit := spec.Limit
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+	if limit > 0 {
+		// Fetch one extra document to know whether another page exists
+		opts.SetLimit(limit + 1)
+	}
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var raws []bson.Raw
+	for cursor.Next(ctx) {
+		raws = append(raws, append(bson.Raw(nil), cursor.Current...))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if limit > 0 && int64(len(raws)) > limit {
+		raws = raws[:limit]
+		last := raws[len(raws)-1]
+		id, _ := last.Lookup("_id").ObjectIDOK()
+		var value interface{}
+		if v, err := last.LookupErr(sortField); err == nil {
+			value = v
+		}
+		token, err := bson.Marshal(bson.D{
+			{Key: "s", Value: sortField},
+			{Key: "d", Value: spec.SortDesc},
+			{Key: "v", Value: value},
+			{Key: "id", Value: id},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		nextCursor = base64.RawURLEncoding.EncodeToString(token)
+	}
+
+	results := make([]T, 0, len(raws))
+	for _, raw := range raws {
+		var item T
+		if err := bson.Unmarshal(raw, &item); err != nil {
+			return nil, "", err
+		}
+		results = append(results, item)
+	}
+	return results, nextCursor, nil
+}
+
+func containsString(slice []string, s string) bool {
+	for _, v := range slice {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
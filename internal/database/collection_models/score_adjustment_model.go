@@ -0,0 +1,169 @@
+package collectionmodels
+
+import (
+	"context"
+	"errors"
+	"performance-dashboard-backend/internal/calendar"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	AdjustmentPending  = "pending"
+	AdjustmentApproved = "approved"
+	AdjustmentRejected = "rejected"
+)
+
+// AdjustmentCategories are the accepted values of ScoreAdjustment.Category
+var AdjustmentCategories = []string{"urgent-fix", "mentoring", "rework", "other"}
+
+var (
+	ErrInvalidAdjustmentCategory = errors.New("invalid category, expected urgent-fix, mentoring, rework or other")
+	ErrAdjustmentReviewed        = errors.New("adjustment has already been reviewed")
+)
+
+// ScoreAdjustment grants or, with a negative Point, deducts points of a member for one week, for work that is not
+// an Asana task. Team is the team credited, the member must belong to it that week. Only approved adjustments count.
+type ScoreAdjustment struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	Email      string             `bson:"email"`
+	Team       string             `bson:"team"`
+	WeekStart  time.Time          `bson:"week_start"`
+	Point      float64            `bson:"point"`
+	Category   string             `bson:"category"`
+	Reason     string             `bson:"reason"`
+	Status     string             `bson:"status"`
+	CreatedBy  string             `bson:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	ReviewedBy string             `bson:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time         `bson:"reviewed_at,omitempty"`
+
+	SoftDelete `bson:",inline"`
+}
+
+// GetApprovedAdjustments returns the approved adjustments of the weeks starting in [dateFrom, dateTo], of the given
+// members, or of the given teams for KindTeam. Adjustments are not tied to a project, there are none for KindProject.
+func GetApprovedAdjustments(client *mongo.Client, dbName, collectionName string, identifiers []string, kind IdentifierKind, dateFrom, dateTo time.Time) ([]ScoreAdjustment, error) {
+	if kind == KindProject {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	key := "email"
+	if kind == KindTeam {
+		key = "team"
+	}
+	filter := bson.M{
+		key:          bson.M{"$in": identifiers},
+		"status":     AdjustmentApproved,
+		"week_start": bson.M{"$gte": dateFrom, "$lte": dateTo},
+	}
+	cursor, err := collection.Find(ctx, activeFilter(filter, false))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var adjustments []ScoreAdjustment
+	if err = cursor.All(ctx, &adjustments); err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
+
+var scoreAdjustmentSortFields = []string{"email", "team", "week_start", "point", "category", "status", "created_at"}
+
+// GetScoreAdjustmentsPage returns one page of adjustments matching the spec and status (any when empty),
+// plus the cursor of the next page
+func GetScoreAdjustmentsPage(client *mongo.Client, dbName, collectionName string, spec QuerySpec, status string) ([]ScoreAdjustment, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	filter := bson.M{}
+	if spec.Team != "" {
+		filter["team"] = spec.Team
+	}
+	if spec.Member != "" {
+		filter["email"] = spec.Member
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+	if r := spec.dateRangeFilter(); r != nil {
+		filter["week_start"] = r
+	}
+	return findPage[ScoreAdjustment](ctx, collection, activeFilter(filter, spec.IncludeDeleted), spec, scoreAdjustmentSortFields)
+}
+
+// InsertScoreAdjustment adds an adjustment, its week start is moved to the start of its week
+func InsertScoreAdjustment(client *mongo.Client, dbName, collectionName string, adjustment *ScoreAdjustment) error {
+	if !containsString(AdjustmentCategories, adjustment.Category) {
+		return ErrInvalidAdjustmentCategory
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	adjustment.WeekStart = calendar.StartOfWeek(adjustment.WeekStart)
+	res, err := collection.InsertOne(ctx, adjustment)
+	if err != nil {
+		return err
+	}
+	adjustment.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdateScoreAdjustment changes the point, category and reason of a pending adjustment
+func UpdateScoreAdjustment(client *mongo.Client, dbName, collectionName string, adjustment *ScoreAdjustment) error {
+	if !containsString(AdjustmentCategories, adjustment.Category) {
+		return ErrInvalidAdjustmentCategory
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	return updatePending(ctx, collection, adjustment.ID, bson.M{
+		"point":    adjustment.Point,
+		"category": adjustment.Category,
+		"reason":   adjustment.Reason,
+	})
+}
+
+// ReviewScoreAdjustment approves or rejects a pending adjustment
+func ReviewScoreAdjustment(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, approve bool, reviewedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	status := AdjustmentRejected
+	if approve {
+		status = AdjustmentApproved
+	}
+	return updatePending(ctx, collection, id, bson.M{"status": status, "reviewed_by": reviewedBy, "reviewed_at": time.Now()})
+}
+
+func DeleteScoreAdjustment(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	return softDeleteOne(ctx, collection, bson.M{"_id": id}, deletedBy)
+}
+
+// updatePending sets fields on a pending adjustment, ErrAdjustmentReviewed when it was already reviewed
+func updatePending(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID, set bson.M) error {
+	res, err := collection.UpdateOne(ctx, activeFilter(bson.M{"_id": id, "status": AdjustmentPending}, false), bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+	n, err := collection.CountDocuments(ctx, activeFilter(bson.M{"_id": id}, false))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return ErrAdjustmentReviewed
+}
@@ -0,0 +1,156 @@
+package collectionmodels
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	DisputeOpen     = "open"
+	DisputeAccepted = "accepted"
+	DisputeRejected = "rejected"
+)
+
+var (
+	ErrDisputeOpen     = errors.New("task already has an open dispute")
+	ErrDisputeResolved = errors.New("dispute has already been resolved")
+)
+
+// ScoreDispute contests the level or tools a completed task was scored with. Level and Tools are the scoring of
+// the task when the dispute was opened, accepting it gives the task the proposed ones.
+type ScoreDispute struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	TaskID        primitive.ObjectID `bson:"task_id"`
+	AsanaTaskID   string             `bson:"asana_task_id"`
+	TaskName      string             `bson:"task_name"`
+	Email         string             `bson:"email"`
+	Team          string             `bson:"team"`
+	DoneDate      time.Time          `bson:"done_date"`
+	Level         int                `bson:"level"`
+	Tools         []int              `bson:"tools"`
+	ProposedLevel int                `bson:"proposed_level"`
+	ProposedTools []int              `bson:"proposed_tools"`
+	Reason        string             `bson:"reason"`
+	Status        string             `bson:"status"`
+	Comments      []DisputeComment   `bson:"comments"`
+	CreatedBy     string             `bson:"created_by"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	ResolvedBy    string             `bson:"resolved_by,omitempty"`
+	ResolvedAt    *time.Time         `bson:"resolved_at,omitempty"`
+	Resolution    string             `bson:"resolution,omitempty"`
+}
+
+type DisputeComment struct {
+	Author    string    `bson:"author"`
+	Body      string    `bson:"body"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+var scoreDisputeSortFields = []string{"email", "team", "done_date", "status", "created_at"}
+
+// GetScoreDisputesPage returns one page of disputes matching the spec and status (any when empty),
+// plus the cursor of the next page. The date range applies to the done date of the tasks.
+func GetScoreDisputesPage(client *mongo.Client, dbName, collectionName string, spec QuerySpec, status string) ([]ScoreDispute, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	filter := bson.M{}
+	if spec.Team != "" {
+		filter["team"] = spec.Team
+	}
+	if spec.Member != "" {
+		filter["email"] = spec.Member
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+	if r := spec.dateRangeFilter(); r != nil {
+		filter["done_date"] = r
+	}
+	return findPage[ScoreDispute](ctx, collection, filter, spec, scoreDisputeSortFields)
+}
+
+// InsertScoreDispute opens a dispute, ErrDisputeOpen when the task already has an open one
+func InsertScoreDispute(client *mongo.Client, dbName, collectionName string, dispute *ScoreDispute) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	n, err := collection.CountDocuments(ctx, bson.M{"task_id": dispute.TaskID, "status": DisputeOpen})
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrDisputeOpen
+	}
+	dispute.Status = DisputeOpen
+	if dispute.Comments == nil {
+		dispute.Comments = []DisputeComment{}
+	}
+	res, err := collection.InsertOne(ctx, dispute)
+	if err != nil {
+		return err
+	}
+	dispute.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// AddDisputeComment appends a comment to an open dispute
+func AddDisputeComment(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, comment DisputeComment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	return updateOpenDispute(ctx, collection, id, bson.M{"$push": bson.M{"comments": comment}})
+}
+
+// ResolveScoreDispute accepts or rejects an open dispute
+func ResolveScoreDispute(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, accept bool, resolvedBy, resolution string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	status := DisputeRejected
+	if accept {
+		status = DisputeAccepted
+	}
+	return updateOpenDispute(ctx, collection, id, bson.M{"$set": bson.M{
+		"status":      status,
+		"resolved_by": resolvedBy,
+		"resolved_at": time.Now(),
+		"resolution":  resolution,
+	}})
+}
+
+// ReopenScoreDispute puts an accepted dispute back to open, when rescoring its task failed after it was claimed
+func ReopenScoreDispute(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id, "status": DisputeAccepted}, bson.M{
+		"$set":   bson.M{"status": DisputeOpen},
+		"$unset": bson.M{"resolved_by": "", "resolved_at": "", "resolution": ""},
+	})
+	return err
+}
+
+// updateOpenDispute applies update to an open dispute, ErrDisputeResolved when it was already resolved
+func updateOpenDispute(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID, update bson.M) error {
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": id, "status": DisputeOpen}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+	n, err := collection.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return ErrDisputeResolved
+}
@@ -0,0 +1,95 @@
+package collectionmodels
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SoftDelete marks a document as deleted without removing it, so historical
+// data that references it (completed tasks, performance) can still be resolved.
+type SoftDelete struct {
+	DeletedAt *time.Time `bson:"deleted_at,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty"`
+}
+
+// activeFilter hides soft deleted documents unless includeDeleted is set
+func activeFilter(filter bson.M, includeDeleted bool) bson.M {
+	if includeDeleted {
+		return filter
+	}
+	res := bson.M{"deleted_at": nil}
+	for k, v := range filter {
+		res[k] = v
+	}
+	return res
+}
+
+// softDeleteOne marks the active document matching filter as deleted.
+// Returns mongo.ErrNoDocuments when there is nothing to delete.
+func softDeleteOne(ctx context.Context, collection *mongo.Collection, filter bson.M, deletedBy string) error {
+	res, err := collection.UpdateOne(ctx, activeFilter(filter, false), bson.M{"$set": bson.M{
+		"deleted_at": time.Now(),
+		"deleted_by": deletedBy,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// restoreOne clears the deletion marker of the deleted document matching filter.
+// Returns mongo.ErrNoDocuments when there is nothing to restore.
+func restoreOne(ctx context.Context, collection *mongo.Collection, filter bson.M) error {
+	deleted := bson.M{"deleted_at": bson.M{"$ne": nil}}
+	for k, v := range filter {
+		deleted[k] = v
+	}
+	res, err := collection.UpdateOne(ctx, deleted, bson.M{"$unset": bson.M{
+		"deleted_at": "",
+		"deleted_by": "",
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// insertOrReplaceDeleted inserts doc, reusing a soft deleted document with the same key if there is one.
+// This keeps unique indexes from blocking a record that was deleted and is being added again.
+func insertOrReplaceDeleted(ctx context.Context, collection *mongo.Collection, key bson.M, doc interface{}) error {
+	deleted := bson.M{"deleted_at": bson.M{"$ne": nil}}
+	for k, v := range key {
+		deleted[k] = v
+	}
+	res, err := collection.ReplaceOne(ctx, deleted, doc)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+	_, err = collection.InsertOne(ctx, doc)
+	return err
+}
+
+// FindByID returns the document with id, deleted or not
+func FindByID[T any](client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) (*T, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	var doc T
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
@@ -0,0 +1,98 @@
+package collectionmodels
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TargetWeight configures how a team target is split between the members without an explicit target.
+// The document with an empty Team is the default for teams without their own.
+type TargetWeight struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	Team             string             `bson:"team"`
+	RoleWeights      map[string]float64 `bson:"role_weights"`
+	SeniorityWeights []SeniorityWeight  `bson:"seniority_weights"`
+}
+
+// SeniorityWeight applies to members who joined at least MinYears ago
+type SeniorityWeight struct {
+	MinYears float64 `bson:"min_years"`
+	Weight   float64 `bson:"weight"`
+}
+
+func GetAllTargetWeights(client *mongo.Client, dbName, collName string) ([]TargetWeight, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var weights []TargetWeight
+	if err = cursor.All(ctx, &weights); err != nil {
+		return nil, err
+	}
+	return weights, nil
+}
+
+// UpsertTargetWeight replaces the weights of weight.Team
+func UpsertTargetWeight(client *mongo.Client, dbName, collName string, weight *TargetWeight) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	_, err := collection.UpdateOne(ctx, bson.M{"team": weight.Team}, bson.M{"$set": bson.M{
+		"role_weights":      weight.RoleWeights,
+		"seniority_weights": weight.SeniorityWeights,
+	}}, options.Update().SetUpsert(true))
+	return err
+}
+
+// TargetWeightForTeam returns the weights of team, the default ones, or nil when neither exists
+func TargetWeightForTeam(weights []TargetWeight, team string) *TargetWeight {
+	var def *TargetWeight
+	for i := range weights {
+		if weights[i].Team == team {
+			return &weights[i]
+		}
+		if weights[i].Team == "" {
+			def = &weights[i]
+		}
+	}
+	return def
+}
+
+// MemberWeight is role weight * seniority weight * work percent / 100 of m in team at t.
+// Missing weights count as 1, so with no configuration every full time member has the same share.
+func (w *TargetWeight) MemberWeight(m *Member, team string, t time.Time) float64 {
+	weight := 1.0
+	if w != nil {
+		for _, a := range m.MembershipsAt(t) {
+			if rw, ok := w.RoleWeights[a.Role]; a.Team == team && ok {
+				weight *= rw
+				break
+			}
+		}
+		if m.JoinedAt != nil {
+			years := t.Sub(*m.JoinedAt).Hours() / 24 / 365
+			best := -1.0
+			seniority := 1.0
+			for _, s := range w.SeniorityWeights {
+				if years >= s.MinYears && s.MinYears > best {
+					best = s.MinYears
+					seniority = s.Weight
+				}
+			}
+			weight *= seniority
+		}
+	}
+	if m.WorkPercent > 0 {
+		weight *= float64(m.WorkPercent) / 100
+	}
+	return weight
+}
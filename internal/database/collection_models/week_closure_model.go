@@ -0,0 +1,146 @@
+package collectionmodels
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrWeekClosed        = errors.New("the change affects a closed week")
+	ErrWeekAlreadyClosed = errors.New("week is already closed for this team")
+	ErrWeekNotEnded      = errors.New("week has not ended yet")
+)
+
+// WeekClosure locks the scores of a team for one week, along with those of the members who belonged to it that week.
+// Reopening soft deletes the closure, DeletedBy is then who reopened the week.
+type WeekClosure struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Team      string             `bson:"team"`
+	WeekStart time.Time          `bson:"week_start"`
+	WeekEnd   time.Time          `bson:"week_end"`
+	Members   []string           `bson:"members"`
+	ClosedBy  string             `bson:"closed_by"`
+	ClosedAt  time.Time          `bson:"closed_at"`
+
+	SoftDelete `bson:",inline"`
+}
+
+// InsertWeekClosure closes a week, it must not be closed already for the team.
+// The unique index on team, week_start and deleted_at rejects a second active closure, even from a concurrent call.
+func InsertWeekClosure(client *mongo.Client, dbName, collectionName string, closure *WeekClosure) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	res, err := collection.InsertOne(ctx, closure)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrWeekAlreadyClosed
+	}
+	if err != nil {
+		return err
+	}
+	closure.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func GetWeekClosure(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) (*WeekClosure, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	var closure WeekClosure
+	if err := collection.FindOne(ctx, activeFilter(bson.M{"_id": id}, false)).Decode(&closure); err != nil {
+		return nil, err
+	}
+	return &closure, nil
+}
+
+// GetWeekClosuresOfWeek returns the active closures of every team for the week starting at weekStart
+func GetWeekClosuresOfWeek(client *mongo.Client, dbName, collectionName string, weekStart time.Time) ([]WeekClosure, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	cursor, err := collection.Find(ctx, activeFilter(bson.M{"week_start": weekStart}, false))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var closures []WeekClosure
+	if err = cursor.All(ctx, &closures); err != nil {
+		return nil, err
+	}
+	return closures, nil
+}
+
+// DeleteWeekClosure removes a closure that could not be completed, it is not kept as history
+func DeleteWeekClosure(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func ReopenWeekClosure(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, reopenedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	return softDeleteOne(ctx, collection, bson.M{"_id": id}, reopenedBy)
+}
+
+var weekClosureSortFields = []string{"team", "week_start", "closed_at", "closed_by"}
+
+// GetWeekClosuresPage returns one page of closures matching the spec, plus the cursor of the next page.
+// IncludeDeleted also lists reopened weeks.
+func GetWeekClosuresPage(client *mongo.Client, dbName, collectionName string, spec QuerySpec) ([]WeekClosure, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	filter := bson.M{}
+	if spec.Team != "" {
+		filter["team"] = spec.Team
+	}
+	if spec.Member != "" {
+		filter["members"] = spec.Member
+	}
+	if r := spec.dateRangeFilter(); r != nil {
+		filter["week_start"] = r
+	}
+	return findPage[WeekClosure](ctx, collection, activeFilter(filter, spec.IncludeDeleted), spec, weekClosureSortFields)
+}
+
+// CheckTeamWeeksOpen returns ErrWeekClosed when a week of one of the teams sharing a day with [dateFrom, dateTo] is closed.
+// Nil teams means any team, a nil dateTo is open ended.
+func CheckTeamWeeksOpen(client *mongo.Client, dbName, collectionName string, teams []string, dateFrom time.Time, dateTo *time.Time) error {
+	filter := bson.M{}
+	if teams != nil {
+		filter["team"] = bson.M{"$in": teams}
+	}
+	return checkWeeksOpen(client, dbName, collectionName, filter, dateFrom, dateTo)
+}
+
+// CheckMemberWeeksOpen returns ErrWeekClosed when a week sharing a day with [dateFrom, dateTo] is closed for a team of the member
+func CheckMemberWeeksOpen(client *mongo.Client, dbName, collectionName, email string, dateFrom time.Time, dateTo *time.Time) error {
+	return checkWeeksOpen(client, dbName, collectionName, bson.M{"members": email}, dateFrom, dateTo)
+}
+
+func checkWeeksOpen(client *mongo.Client, dbName, collectionName string, filter bson.M, dateFrom time.Time, dateTo *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	filter["week_end"] = bson.M{"$gte": dateFrom}
+	if dateTo != nil {
+		filter["week_start"] = bson.M{"$lte": *dateTo}
+	}
+	n, err := collection.CountDocuments(ctx, activeFilter(filter, false))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrWeekClosed
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package collectionmodels
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -9,25 +10,45 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// OrderPriorities are the accepted values of OrderItem.Priority
+var OrderPriorities = []string{PriorityHigh, PriorityNormal, PriorityLow}
+
+var ErrInvalidOrderItem = errors.New("invalid order item")
+
+// WeeklyOrder is what a project asks the teams for in the week of StartWeek. ProjectID is the catalog project the
+// order is for, one order per project and week, Project its name.
 type WeeklyOrder struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty"`
 	StartWeek time.Time          `bson:"start_week"`
 	Goal      string             `bson:"goal"`
 	Strategy  string             `bson:"strategy"`
+	ProjectID int                `bson:"project_id"`
 	Project   string             `bson:"project"`
-	CPP       int                `bson:"art_cpp"`
-	Icon      int                `bson:"art_icon"`
-	Banner    int                `bson:"art_banner"`
-	PLA       int                `bson:"playable"`
-	Video     int                `bson:"video"`
+	Items     []OrderItem        `bson:"items"`
+
+	SoftDelete `bson:",inline"`
+}
+
+// OrderItem asks a team for a quantity of one asset type of the catalog
+type OrderItem struct {
+	AssetType string `bson:"asset_type"`
+	Team      string `bson:"team"`
+	Quantity  int    `bson:"quantity"`
+	Priority  string `bson:"priority"`
+	Notes     string `bson:"notes,omitempty"`
 }
 
 func InsertWeeklyOrder(client *mongo.Client, dbName, collName string, order *WeeklyOrder) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	_, err := collection.InsertOne(ctx, order)
-	return err
+	return insertOrReplaceDeleted(ctx, collection, bson.M{"start_week": order.StartWeek, "project_id": order.ProjectID}, order)
 }
 
 func UpdateWeeklyOrder(client *mongo.Client, dbName, collName string, order *WeeklyOrder) error {
@@ -35,7 +56,7 @@ func UpdateWeeklyOrder(client *mongo.Client, dbName, collName string, order *Wee
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
 	// base on start_week and project to update
-	_, err := collection.UpdateOne(ctx, bson.M{"start_week": order.StartWeek, "project": order.Project}, bson.M{"$set": order})
+	_, err := collection.UpdateOne(ctx, activeFilter(bson.M{"start_week": order.StartWeek, "project_id": order.ProjectID}, false), bson.M{"$set": order})
 	return err
 }
 
@@ -43,10 +64,10 @@ func GetWeeklyOrders(client *mongo.Client, dbName, collName string, startWeek []
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	filter := bson.M{
+	filter := activeFilter(bson.M{
 		"start_week": bson.M{"$in": startWeek},
 		"project":    bson.M{"$in": project},
-	}
+	}, false)
 	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
@@ -60,11 +81,26 @@ func GetWeeklyOrders(client *mongo.Client, dbName, collName string, startWeek []
 	return results, nil
 }
 
-func DeleteWeeklyOrder(client *mongo.Client, dbName, collName string, startWeek time.Time, project string) error {
+func DeleteWeeklyOrder(client *mongo.Client, dbName, collName string, startWeek time.Time, projectID int, deletedBy string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	_, err := collection.DeleteOne(ctx, bson.M{"start_week": startWeek, "project": project})
+	return softDeleteOne(ctx, collection, bson.M{"start_week": startWeek, "project_id": projectID}, deletedBy)
+}
+
+func RestoreWeeklyOrder(client *mongo.Client, dbName, collName string, startWeek time.Time, projectID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	return restoreOne(ctx, collection, bson.M{"start_week": startWeek, "project_id": projectID})
+}
+
+// RenameOrderProject sets the project name shown on the orders of a renamed project
+func RenameOrderProject(client *mongo.Client, dbName, collName string, projectID int, project string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	_, err := collection.UpdateMany(ctx, bson.M{"project_id": projectID}, bson.M{"$set": bson.M{"project": project}})
 	return err
 }
 
@@ -72,7 +108,44 @@ func GetAllWeeklyOrders(client *mongo.Client, dbName, collName string) ([]*Weekl
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	cursor, err := collection.Find(ctx, bson.M{})
+	cursor, err := collection.Find(ctx, activeFilter(bson.M{}, false))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var results []*WeeklyOrder
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+var weeklyOrderSortFields = []string{"start_week", "project", "goal", "strategy"}
+
+// GetWeeklyOrdersPage returns one page of weekly orders matching the spec, plus the cursor of the next page
+func GetWeeklyOrdersPage(client *mongo.Client, dbName, collName string, spec QuerySpec) ([]*WeeklyOrder, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	filter := bson.M{}
+	if spec.Project != "" {
+		filter["project"] = spec.Project
+	}
+	if spec.Team != "" {
+		filter["items.team"] = spec.Team
+	}
+	if r := spec.dateRangeFilter(); r != nil {
+		filter["start_week"] = r
+	}
+	return findPage[*WeeklyOrder](ctx, collection, activeFilter(filter, spec.IncludeDeleted), spec, weeklyOrderSortFields)
+}
+
+// GetWeeklyOrdersByRange returns the orders of the weeks starting in [dateFrom, dateTo]
+func GetWeeklyOrdersByRange(client *mongo.Client, dbName, collName string, dateFrom, dateTo time.Time) ([]*WeeklyOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	cursor, err := collection.Find(ctx, activeFilter(bson.M{"start_week": bson.M{"$gte": dateFrom, "$lte": dateTo}}, false))
 	if err != nil {
 		return nil, err
 	}
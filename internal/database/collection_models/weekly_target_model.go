@@ -2,69 +2,215 @@ package collectionmodels
 
 import (
 	"context"
+	"errors"
+	"performance-dashboard-backend/internal/calendar"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	ErrTargetOverlap    = errors.New("target overlaps an existing target")
+	ErrInvalidDateRange = errors.New("DateTo must not be before DateFrom")
+)
+
+// WeeklyTarget is a recurring rule of a team's target calendar: Point every EveryWeeks weeks
+// from DateFrom, until DateTo or for good when DateTo is nil. The rules of a team never share a week,
+// holiday weeks are changed with a WeeklyTargetOverride.
 type WeeklyTarget struct {
 	ID       primitive.ObjectID `bson:"_id,omitempty"`
 	Team     string             `bson:"team"`
 	Point    int                `bson:"point"`
 	DateFrom time.Time          `bson:"date_from"`
-	DateTo   time.Time          `bson:"date_to"`
+	DateTo   *time.Time         `bson:"date_to"`
+	// EveryWeeks counts weeks from the week of DateFrom, 0 and 1 both mean every week
+	EveryWeeks int `bson:"every_weeks,omitempty"`
+
+	SoftDelete `bson:",inline"`
 }
 
-func GetWeeklyTargetByTeam(client *mongo.Client, dbName, collectionName string, team string, targetTime time.Time) (*WeeklyTarget, error) {
+// AppliesTo reports whether the rule sets the target of the week starting at weekStart
+func (t *WeeklyTarget) AppliesTo(weekStart time.Time) bool {
+	if weekStart.Before(calendar.StartOfWeek(t.DateFrom)) || (t.DateTo != nil && weekStart.After(*t.DateTo)) {
+		return false
+	}
+	if t.EveryWeeks <= 1 {
+		return true
+	}
+	return calendar.WeeksBetween(t.DateFrom, weekStart)%t.EveryWeeks == 0
+}
 
+// GetWeeklyTargetsForTeams returns the rules of teams active at some point of [dateFrom, dateTo]
+func GetWeeklyTargetsForTeams(client *mongo.Client, dbName, collectionName string, teams []string, dateFrom, dateTo time.Time) ([]WeeklyTarget, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	var result WeeklyTarget
-	err := collection.FindOne(ctx, bson.M{"team": team, "date_from": bson.M{"$lte": targetTime}, "date_to": bson.M{"$gte": targetTime}}).Decode(&result)
+	// A rule ending early in the first week still sets its target
+	filter := bson.M{"$and": bson.A{bson.M{"team": bson.M{"$in": teams}}, overlapFilter(calendar.StartOfWeek(dateFrom), &dateTo)}}
+	cursor, err := collection.Find(ctx, activeFilter(filter, false))
 	if err != nil {
 		return nil, err
 	}
-	return &result, nil
+	defer cursor.Close(ctx)
+	var targets []WeeklyTarget
+	if err = cursor.All(ctx, &targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
 }
 
-func UpdateWeeklyTargetByTeam(client *mongo.Client, dbName, collectionName string, target *WeeklyTarget) error {
+// UpdateWeeklyTarget changes the rule with target.ID, including its date range
+func UpdateWeeklyTarget(client *mongo.Client, dbName, collectionName string, target *WeeklyTarget) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.UpdateOne(ctx, bson.M{"team": target.Team, "date_from": target.DateFrom, "date_to": target.DateTo}, bson.M{"$set": target})
-	return err
+	others := bson.M{"team": target.Team, "_id": bson.M{"$ne": target.ID}}
+	dateFrom, dateTo := ruleWeeks(target.DateFrom, target.DateTo)
+	return writeWithoutOverlap(ctx, collection, "team:"+target.Team, others, dateFrom, dateTo, func(ctx context.Context) error {
+		res, err := collection.UpdateOne(ctx, activeFilter(bson.M{"_id": target.ID}, false), bson.M{"$set": bson.M{
+			"team":        target.Team,
+			"point":       target.Point,
+			"date_from":   target.DateFrom,
+			"date_to":     target.DateTo,
+			"every_weeks": target.EveryWeeks,
+		}})
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return mongo.ErrNoDocuments
+		}
+		return nil
+	})
 }
 
+// InsertWeeklyTarget adds a team target, it must not overlap another target of the team
 func InsertWeeklyTarget(client *mongo.Client, dbName, collectionName string, target *WeeklyTarget) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.InsertOne(ctx, target)
-	return err
+	dateFrom, dateTo := ruleWeeks(target.DateFrom, target.DateTo)
+	return writeWithoutOverlap(ctx, collection, "team:"+target.Team, bson.M{"team": target.Team}, dateFrom, dateTo, func(ctx context.Context) error {
+		_, err := collection.InsertOne(ctx, target)
+		return err
+	})
 }
 
-func DeleteWeeklyTarget(client *mongo.Client, dbName, collectionName string, team string, dateFrom, dateTo time.Time) error {
+func DeleteWeeklyTarget(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, deletedBy string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.DeleteOne(ctx, bson.M{"team": team, "date_from": dateFrom, "date_to": dateTo})
-	return err
+	return softDeleteOne(ctx, collection, bson.M{"_id": id}, deletedBy)
+}
+
+func RestoreWeeklyTarget(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	var target WeeklyTarget
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&target); err != nil {
+		return err
+	}
+	// Another target may have been added for the same dates while this one was deleted
+	dateFrom, dateTo := ruleWeeks(target.DateFrom, target.DateTo)
+	return writeWithoutOverlap(ctx, collection, "team:"+target.Team, bson.M{"team": target.Team}, dateFrom, dateTo, func(ctx context.Context) error {
+		return restoreOne(ctx, collection, bson.M{"_id": id})
+	})
 }
 
-func GetAllWeeklyTargets(client *mongo.Client, dbName, collectionName string) ([]WeeklyTarget, error) {
+var weeklyTargetSortFields = []string{"team", "point", "date_from", "date_to"}
+
+// GetWeeklyTargetsPage returns one page of weekly targets matching the spec, plus the cursor of the next page.
+// The start week range keeps every target whose date range overlaps it.
+func GetWeeklyTargetsPage(client *mongo.Client, dbName, collectionName string, spec QuerySpec) ([]WeeklyTarget, string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	cursor, err := collection.Find(ctx, bson.M{})
+	filter := bson.M{}
+	if spec.Team != "" {
+		filter["team"] = spec.Team
+	}
+	if spec.StartWeekFrom != nil {
+		filter["$or"] = bson.A{bson.M{"date_to": nil}, bson.M{"date_to": bson.M{"$gte": *spec.StartWeekFrom}}}
+	}
+	if spec.StartWeekTo != nil {
+		filter["date_from"] = bson.M{"$lte": *spec.StartWeekTo}
+	}
+	return findPage[WeeklyTarget](ctx, collection, activeFilter(filter, spec.IncludeDeleted), spec, weeklyTargetSortFields)
+}
+
+// overlapFilter matches date ranges sharing a day with [dateFrom, dateTo], a nil date_to or dateTo is open ended
+func overlapFilter(dateFrom time.Time, dateTo *time.Time) bson.M {
+	filter := bson.M{"$or": bson.A{bson.M{"date_to": nil}, bson.M{"date_to": bson.M{"$gte": dateFrom}}}}
+	if dateTo != nil {
+		filter["date_from"] = bson.M{"$lte": *dateTo}
+	}
+	return filter
+}
+
+// ruleWeeks widens the dates of a rule to the whole weeks it sets the target of
+func ruleWeeks(dateFrom time.Time, dateTo *time.Time) (time.Time, *time.Time) {
+	if dateTo == nil || dateTo.Before(dateFrom) {
+		// A reversed range is left for checkNoOverlap to reject
+		return calendar.StartOfWeek(dateFrom), dateTo
+	}
+	end := calendar.EndOfWeek(*dateTo)
+	return calendar.StartOfWeek(dateFrom), &end
+}
+
+// checkNoOverlap returns ErrTargetOverlap when an active document matching filter shares a day with [dateFrom, dateTo]
+func checkNoOverlap(ctx context.Context, collection *mongo.Collection, filter bson.M, dateFrom time.Time, dateTo *time.Time) error {
+	if dateTo != nil && dateTo.Before(dateFrom) {
+		return ErrInvalidDateRange
+	}
+	n, err := collection.CountDocuments(ctx, activeFilter(bson.M{"$and": bson.A{filter, overlapFilter(dateFrom, dateTo)}}, false))
 	if err != nil {
-		return nil, err
+		return err
 	}
-	var targets []WeeklyTarget
-	if err = cursor.All(ctx, &targets); err != nil {
-		return nil, err
+	if n > 0 {
+		return ErrTargetOverlap
 	}
-	return targets, nil
+	return nil
+}
+
+// writeWithoutOverlap runs checkNoOverlap then write in one transaction (MongoDB must run as a replica set).
+// Snapshot isolation alone lets two writers that both found no overlap commit, so the transaction first bumps
+// the lock document of owner, the team or member whose targets must not overlap, in the "-locks" collection next
+// to collection. Concurrent writers for the same owner then conflict and the retried one sees the other's write.
+func writeWithoutOverlap(ctx context.Context, collection *mongo.Collection, owner string, filter bson.M, dateFrom time.Time, dateTo *time.Time, write func(ctx context.Context) error) error {
+	session, err := collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if err := lockAndCheckNoOverlap(sc, collection, owner, filter, dateFrom, dateTo); err != nil {
+			return nil, err
+		}
+		return nil, write(sc)
+	})
+	return err
+}
+
+// CheckTeamTargetWritable checks, within the caller's transaction, that the target of team starting on dateFrom
+// can be written without overlapping another target of the team. It takes the same lock as the target writes
+// of the API, for writes that are part of a larger transaction such as an import.
+func CheckTeamTargetWritable(sc mongo.SessionContext, collection *mongo.Collection, team string, dateFrom time.Time, dateTo *time.Time) error {
+	others := bson.M{"team": team, "date_from": bson.M{"$ne": dateFrom}}
+	weeksFrom, weeksTo := ruleWeeks(dateFrom, dateTo)
+	return lockAndCheckNoOverlap(sc, collection, "team:"+team, others, weeksFrom, weeksTo)
+}
+
+// lockAndCheckNoOverlap bumps the lock document of owner then runs checkNoOverlap, in the transaction of sc
+func lockAndCheckNoOverlap(sc mongo.SessionContext, collection *mongo.Collection, owner string, filter bson.M, dateFrom time.Time, dateTo *time.Time) error {
+	locks := collection.Database().Collection(collection.Name() + "-locks")
+	lock := bson.M{"$inc": bson.M{"writes": 1}}
+	if _, err := locks.UpdateOne(sc, bson.M{"_id": owner}, lock, options.Update().SetUpsert(true)); err != nil {
+		return err
+	}
+	return checkNoOverlap(sc, collection, filter, dateFrom, dateTo)
 }
@@ -0,0 +1,114 @@
+package collectionmodels
+
+import (
+	"context"
+	"performance-dashboard-backend/internal/calendar"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WeeklyTargetOverride replaces the target of one team for one week, e.g. 0 for a holiday week
+type WeeklyTargetOverride struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Team      string             `bson:"team"`
+	WeekStart time.Time          `bson:"week_start"`
+	Point     int                `bson:"point"`
+	Reason    string             `bson:"reason"`
+
+	SoftDelete `bson:",inline"`
+}
+
+// GetWeeklyTargetOverrides returns the overrides of teams for the weeks starting in [dateFrom, dateTo]
+func GetWeeklyTargetOverrides(client *mongo.Client, dbName, collectionName string, teams []string, dateFrom, dateTo time.Time) ([]WeeklyTargetOverride, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	filter := bson.M{
+		"team":       bson.M{"$in": teams},
+		"week_start": bson.M{"$gte": calendar.StartOfWeek(dateFrom), "$lte": dateTo},
+	}
+	cursor, err := collection.Find(ctx, activeFilter(filter, false))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var overrides []WeeklyTargetOverride
+	if err = cursor.All(ctx, &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+var weeklyTargetOverrideSortFields = []string{"team", "week_start", "point"}
+
+// GetWeeklyTargetOverridesPage returns one page of overrides matching the spec, plus the cursor of the next page
+func GetWeeklyTargetOverridesPage(client *mongo.Client, dbName, collectionName string, spec QuerySpec) ([]WeeklyTargetOverride, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	filter := bson.M{}
+	if spec.Team != "" {
+		filter["team"] = spec.Team
+	}
+	if r := spec.dateRangeFilter(); r != nil {
+		filter["week_start"] = r
+	}
+	return findPage[WeeklyTargetOverride](ctx, collection, activeFilter(filter, spec.IncludeDeleted), spec, weeklyTargetOverrideSortFields)
+}
+
+// SetWeeklyTargetOverride sets the override of the week containing override.WeekStart, replacing any previous one
+func SetWeeklyTargetOverride(client *mongo.Client, dbName, collectionName string, override *WeeklyTargetOverride) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	override.WeekStart = calendar.StartOfWeek(override.WeekStart)
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"team": override.Team, "week_start": override.WeekStart},
+		bson.M{
+			"$set":   bson.M{"point": override.Point, "reason": override.Reason},
+			"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+		},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+func DeleteWeeklyTargetOverride(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	return softDeleteOne(ctx, collection, bson.M{"_id": id}, deletedBy)
+}
+
+// EffectiveWeeklyTarget is the resolved target of a team for one week.
+// Source is "override", "rule", or "none" when no target is set.
+type EffectiveWeeklyTarget struct {
+	Team      string              `bson:"team"`
+	WeekStart time.Time           `bson:"week_start"`
+	WeekEnd   time.Time           `bson:"week_end"`
+	Point     int                 `bson:"point"`
+	Source    string              `bson:"source"`
+	SourceID  *primitive.ObjectID `bson:"source_id,omitempty"`
+}
+
+// ResolveWeeklyTarget picks the target of team for the week [weekStart, weekEnd]: its override, else the rule applying to it
+func ResolveWeeklyTarget(rules []WeeklyTarget, overrides []WeeklyTargetOverride, team string, weekStart, weekEnd time.Time) EffectiveWeeklyTarget {
+	res := EffectiveWeeklyTarget{Team: team, WeekStart: weekStart, WeekEnd: weekEnd, Source: "none"}
+	start := calendar.StartOfWeek(weekStart)
+	for i := range overrides {
+		if overrides[i].Team == team && overrides[i].WeekStart.Equal(start) {
+			res.Point, res.Source, res.SourceID = overrides[i].Point, "override", &overrides[i].ID
+			return res
+		}
+	}
+	for i := range rules {
+		if rules[i].Team == team && rules[i].AppliesTo(weekStart) {
+			res.Point, res.Source, res.SourceID = rules[i].Point, "rule", &rules[i].ID
+			return res
+		}
+	}
+	return res
+}
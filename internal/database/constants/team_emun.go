@@ -7,4 +7,5 @@ const (
 	Concept     string = "Concept Creative"
 	Playable    string = "PLA Creative"
 	Research    string = "Research Creative"
+	UA          string = "UA"
 )
@@ -3,8 +3,11 @@ package db_handler
 import (
 	"context"
 	"log"
-	"os"
+	"performance-dashboard-backend/internal/calendar"
 	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"slices"
+	"sort"
+	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,12 +16,13 @@ import (
 )
 
 var client *mongo.Client
+var databaseName string
 
-func ConnectMongoDB() error {
+func ConnectMongoDB(mongoURI, dbName string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	mongoURI := os.Getenv("MONGO_URI")
 	var err error
+	databaseName = dbName
 	client, err = mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
 	if err != nil {
 		return err
@@ -54,11 +58,11 @@ type TeamRole struct {
 	Role string `bson:"role"`
 }
 
-func GetPerformancePoint(uri, dbName, collName, identifier string, startDate, endDate time.Time, isTeam bool) ([]*PerformancePoint, error) {
+func GetPerformancePoint(dbName string, collections collectionmodels.Collections, identifier string, startDate, endDate time.Time, isTeam bool) ([]*PerformancePoint, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 	// defer client.Disconnect(ctx)
-	collection := client.Database(dbName).Collection(collName)
+	collection := client.Database(dbName).Collection(collections.CompletedTask)
 
 	var identifierKey string
 	if isTeam {
@@ -83,13 +87,14 @@ func GetPerformancePoint(uri, dbName, collName, identifier string, startDate, en
 					{Key: "date", Value: "$done_date"},
 					{Key: "unit", Value: "week"},
 					{Key: "binSize", Value: 1},
-					{Key: "startOfWeek", Value: "monday"},
+					{Key: "startOfWeek", Value: calendar.MongoStartOfWeek()},
+					{Key: "timezone", Value: calendar.MongoTimezone()},
 				}},
 			}},
 		}}},
 		// 3. $lookup level theo team
 		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "level"},
+			{Key: "from", Value: collections.Level},
 			{Key: "localField", Value: "team"},
 			{Key: "foreignField", Value: "team"},
 			{Key: "as", Value: "level_info"},
@@ -117,7 +122,7 @@ func GetPerformancePoint(uri, dbName, collName, identifier string, startDate, en
 
 		// 5. $lookup creative-tool theo tool
 		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "creative-tool"},
+			{Key: "from", Value: collections.CreativeTools},
 			{Key: "localField", Value: "tool"},
 			{Key: "foreignField", Value: "index"},
 			{Key: "as", Value: "tool_info"},
@@ -277,7 +282,9 @@ func GetPerformancePoint(uri, dbName, collName, identifier string, startDate, en
 	return results, nil
 }
 
-func GetMembersByTeam(uri, dbName, collName string, team string) ([]*collectionmodels.Member, error) {
+// GetMembersByTeam lists the current members of a team. With includeDeleted it also returns
+// deleted members and anyone who belonged to the team in the past.
+func GetMembersByTeam(dbName, collName string, team string, includeDeleted bool) ([]*collectionmodels.Member, error) {
 	// Example body request
 	// 	{
 	//     "teams": ["Art Creative"]
@@ -288,9 +295,14 @@ func GetMembersByTeam(uri, dbName, collName string, team string) ([]*collectionm
 	// defer client.Disconnect(ctx)
 	collection := client.Database(dbName).Collection(collName)
 
+	notDeleted := bson.M{}
+	if !includeDeleted {
+		notDeleted["deleted_at"] = nil
+	}
+
 	// if team == "" or null return all collection
 	if team == "" {
-		cursor, err := collection.Find(ctx, bson.M{})
+		cursor, err := collection.Find(ctx, notDeleted)
 		if err != nil {
 			return nil, err
 		}
@@ -304,7 +316,13 @@ func GetMembersByTeam(uri, dbName, collName string, team string) ([]*collectionm
 	}
 
 	filter := bson.M{
-		"team": team,
+		"assignments": collectionmodels.ActiveMembershipFilter(team, time.Now()),
+	}
+	if includeDeleted {
+		filter = bson.M{"assignments.team": team}
+	}
+	for k, v := range notDeleted {
+		filter[k] = v
 	}
 	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
@@ -324,11 +342,11 @@ func GetMembersByTeam(uri, dbName, collName string, team string) ([]*collectionm
 	return results, nil
 }
 
-func IsEmailInDatabase(uri, dbName, collName, email string) (bool, error) {
+func IsEmailInDatabase(dbName, collName, email string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	filter := bson.M{"email": email}
+	filter := bson.M{"email": email, "deleted_at": nil}
 	count, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return false, err
@@ -336,19 +354,33 @@ func IsEmailInDatabase(uri, dbName, collName, email string) (bool, error) {
 	return count > 0, nil
 }
 
-func GetMemberRoles(uri, dbName, collName, email string) ([]*TeamRole, error) {
+// GetMemberRoles returns the current (team, role) memberships of a member
+func GetMemberRoles(dbName, collName, email string) ([]*TeamRole, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
+	now := time.Now()
 
 	pipeline := mongo.Pipeline{
 		{{
-			Key: "$match", Value: bson.D{{Key: "email", Value: email}},
+			Key: "$match", Value: bson.D{{Key: "email", Value: email}, {Key: "deleted_at", Value: nil}},
+		}},
+		{{
+			Key: "$unwind", Value: "$assignments",
+		}},
+		{{
+			Key: "$match", Value: bson.D{
+				{Key: "assignments.from", Value: bson.D{{Key: "$lte", Value: now}}},
+				{Key: "$or", Value: bson.A{
+					bson.D{{Key: "assignments.to", Value: nil}},
+					bson.D{{Key: "assignments.to", Value: bson.D{{Key: "$gt", Value: now}}}},
+				}},
+			},
 		}},
 		{{
 			Key: "$project", Value: bson.D{
-				{Key: "role", Value: 1},
-				{Key: "team", Value: 1},
+				{Key: "role", Value: "$assignments.role"},
+				{Key: "team", Value: "$assignments.team"},
 				{Key: "_id", Value: 0},
 			},
 		}},
@@ -367,14 +399,30 @@ func GetMemberRoles(uri, dbName, collName, email string) ([]*TeamRole, error) {
 	return results, nil
 }
 
-func GetAllTeams(uri, dbName, collName string) ([]*Team, error) {
+// GetAllTeams lists the teams that had at least one member assigned at the given time
+func GetAllTeams(dbName, collName string, at time.Time) ([]*Team, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
 	pipeline := mongo.Pipeline{
+		{{
+			Key: "$match", Value: bson.D{{Key: "deleted_at", Value: nil}},
+		}},
+		{{
+			Key: "$unwind", Value: "$assignments",
+		}},
+		{{
+			Key: "$match", Value: bson.D{
+				{Key: "assignments.from", Value: bson.D{{Key: "$lte", Value: at}}},
+				{Key: "$or", Value: bson.A{
+					bson.D{{Key: "assignments.to", Value: nil}},
+					bson.D{{Key: "assignments.to", Value: bson.D{{Key: "$gt", Value: at}}}},
+				}},
+			},
+		}},
 		{{
 			Key: "$group", Value: bson.D{
-				{Key: "_id", Value: "$team"},
+				{Key: "_id", Value: "$assignments.team"},
 			},
 		}},
 	}
@@ -391,50 +439,55 @@ func GetAllTeams(uri, dbName, collName string) ([]*Team, error) {
 	return results, nil
 }
 
-func GetTeamWeeklyTarget(uri, dbName, collName, team string) (*TeamWeeklyTarget, error) {
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	collection := client.Database(dbName).Collection(collName)
-	pipeline := mongo.Pipeline{
-		bson.D{{Key: "$match", Value: bson.D{
-			{Key: "date_from", Value: bson.D{{Key: "$lte", Value: time.Now()}}},
-			{Key: "date_to", Value: bson.D{{Key: "$gte", Value: time.Now()}}},
-			{Key: "team", Value: team},
-		}}},
-		bson.D{{Key: "$project", Value: bson.D{
-			{Key: "_id", Value: 0},
-			{Key: "team", Value: 1},
-			{Key: "point", Value: 1},
-		}}},
-	}
-
-	var result TeamWeeklyTarget
-	cursor, err := collection.Aggregate(ctx, pipeline)
+// GetTeamWeeklyTarget returns the target of team for the current week, nil when none is set
+func GetTeamWeeklyTarget(dbName, team string) (*TeamWeeklyTarget, error) {
+	now := time.Now()
+	targets, err := GetEffectiveWeeklyTargets(client, dbName, []string{team}, now, now)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
-
-	if cursor.Next(ctx) {
-		if err := cursor.Decode(&result); err != nil {
-			return nil, err
-		}
-		return &result, nil
+	if len(targets) == 0 || targets[0].Source == "none" {
+		return nil, nil
 	}
-	return nil, nil
+	return &TeamWeeklyTarget{Team: team, WeeklyTarget: int32(targets[0].Point)}, nil
 }
 
 func GetMongoClient() *mongo.Client {
 	return client
 }
 
+// GetDatabaseName returns the name of the database set by ConnectMongoDB
+func GetDatabaseName() string {
+	return databaseName
+}
+
+var collections collectionmodels.Collections
+
+// ConfigureCollections checks the collection names against the database and keeps them, after ConnectMongoDB
+func ConfigureCollections(c collectionmodels.Collections) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Validate(ctx, client.Database(databaseName)); err != nil {
+		return err
+	}
+	collections = c
+	return nil
+}
+
+// GetCollections returns the collection names set by ConfigureCollections
+func GetCollections() collectionmodels.Collections {
+	return collections
+}
+
 // Lấy tổng điểm trong khoảng thời gian, không chia theo tuần
+// PerformancePointTotal is the points of an identifier over a period. TotalPerformancePoint includes
+// TotalAdjustmentPoint, the sum of the approved manual adjustments of the period.
 type PerformancePointTotal struct {
 	TotalPerformancePoint     float64 `bson:"total_performance_point"`
 	TotalCreativeProcessPoint float64 `bson:"total_creative_process_point"`
 	TotalCreativeTaskPoint    float64 `bson:"total_creative_task_point"`
 	TotalBasePoint            float64 `bson:"total_base_point"`
+	TotalAdjustmentPoint      float64 `bson:"total_adjustment_point"`
 	Identifier                string  `bson:"identifier"`
 }
 
@@ -444,10 +497,10 @@ type PerformancePointTotalWithTime struct {
 	TotalPerformancePoint PerformancePointTotal `bson:"total_performance_point"`
 }
 
-func GetPerformancePointTotal(uri, dbName, collName, identifier string, startDate, endDate time.Time, isTeam bool) (*PerformancePointTotal, error) {
+func GetPerformancePointTotal(dbName string, collections collectionmodels.Collections, identifier string, startDate, endDate time.Time, isTeam bool) (*PerformancePointTotal, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
-	collection := client.Database(dbName).Collection(collName)
+	collection := client.Database(dbName).Collection(collections.CompletedTask)
 
 	var identifierKey string
 	if isTeam {
@@ -468,7 +521,7 @@ func GetPerformancePointTotal(uri, dbName, collName, identifier string, startDat
 
 		// 2. Lookup level theo team
 		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "level"},
+			{Key: "from", Value: collections.Level},
 			{Key: "localField", Value: "team"},
 			{Key: "foreignField", Value: "team"},
 			{Key: "as", Value: "level_info"},
@@ -496,7 +549,7 @@ func GetPerformancePointTotal(uri, dbName, collName, identifier string, startDat
 
 		// 4. Lookup creative-tool theo tool
 		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "creative-tool"},
+			{Key: "from", Value: collections.CreativeTools},
 			{Key: "localField", Value: "tool"},
 			{Key: "foreignField", Value: "index"},
 			{Key: "as", Value: "tool_info"},
@@ -650,78 +703,213 @@ func GetPerformancePointTotal(uri, dbName, collName, identifier string, startDat
 	return &results[0], nil
 }
 
-func GetPerformancePoints(client *mongo.Client, dbName, collectionName string, identifier string, startDate, endDate time.Time, isTeam, isWeekly bool) ([]PerformancePointTotalWithTime, error) {
+// GetPerformancePoints returns the points of an identifier for each bucket of the granularity between startDate and endDate.
+// Buckets without completed tasks are returned with zero points so that series have no gaps.
+func GetPerformancePoints(client *mongo.Client, dbName string, collections collectionmodels.Collections, identifier string, startDate, endDate time.Time, kind collectionmodels.IdentifierKind, granularity calendar.Granularity) ([]PerformancePointTotalWithTime, error) {
+	return GetPerformancePointsBatch(client, dbName, collections, []string{identifier}, startDate, endDate, kind, granularity)
+}
 
-	// Slide the startDate to to the EndDate using Monday
-	level, err := collectionmodels.GetAllLevels(client, dbName, os.Getenv("MONGODB_COLLECTION_LEVEL"))
+// GetPerformancePointsBatch is GetPerformancePoints for many identifiers at once: levels, tools and member
+// assignments are loaded once, the tasks of every identifier over the whole range are fetched with a single query
+// and bucketed in memory. Weekly requests read whole weeks from the performance snapshots when they are up to date.
+// Results are ordered by identifier then bucket.
+func GetPerformancePointsBatch(client *mongo.Client, dbName string, collections collectionmodels.Collections, identifiers []string, startDate, endDate time.Time, kind collectionmodels.IdentifierKind, granularity calendar.Granularity) ([]PerformancePointTotalWithTime, error) {
+	if len(identifiers) == 0 {
+		return nil, nil
+	}
+	rules, err := loadScoringRules(client, dbName, collections)
 	if err != nil {
 		return nil, err
 	}
+	buckets := granularity.Buckets(startDate, endDate)
+	if len(buckets) == 0 {
+		return nil, nil
+	}
 
-	toolList, err := collectionmodels.GetAllCreativeTools(client, dbName, os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"))
+	totals := make(map[string][]PerformancePointTotal, len(identifiers))
+	for _, id := range identifiers {
+		totals[id] = make([]PerformancePointTotal, len(buckets))
+	}
+	var cached map[string][]bool
+	if granularity.Unit == calendar.UnitWeek {
+		if cached, err = readSnapshots(client, dbName, collections, identifiers, kind, buckets, rules.version, totals); err != nil {
+			return nil, err
+		}
+	}
+	if err := computePoints(client, dbName, collections, identifiers, kind, buckets, rules, totals, cached); err != nil {
+		return nil, err
+	}
+	if granularity.Unit == calendar.UnitWeek {
+		if _, err := writeSnapshots(client, dbName, collections, identifiers, kind, buckets, rules.version, totals, cached, false); err != nil {
+			// Snapshots only save work, the computed points are still right
+			log.Println("Performance snapshot error:", err)
+		}
+	}
+	// Adjustments are kept out of snapshots, they can be approved after a week is closed
+	if err := addAdjustments(client, dbName, collections, identifiers, kind, buckets, totals); err != nil {
+		return nil, err
+	}
+
+	results := make([]PerformancePointTotalWithTime, 0, len(identifiers)*len(buckets))
+	for _, id := range identifiers {
+		for b, bucket := range buckets {
+			total := totals[id][b]
+			total.Identifier = id
+			results = append(results, PerformancePointTotalWithTime{
+				StartDate:             bucket[0],
+				EndDate:               bucket[1],
+				TotalPerformancePoint: total,
+			})
+		}
+	}
+	return results, nil
+}
+
+// GetTeamMemberPoints returns the points each assignee earned for team over the weeks from startDate to endDate,
+// ordered by email: the tasks credited to team as for its KindTeam points and the approved adjustments of team,
+// so that the rows add up to the weekly points of the team. Assignees without a member record are included.
+func GetTeamMemberPoints(client *mongo.Client, dbName string, collections collectionmodels.Collections, team string, startDate, endDate time.Time) ([]PerformancePointTotal, error) {
+	buckets := calendar.Weekly.Buckets(startDate, endDate)
+	if len(buckets) == 0 {
+		return nil, nil
+	}
+	rangeStart, rangeEnd := buckets[0][0], buckets[len(buckets)-1][1]
+	rules, err := loadScoringRules(client, dbName, collections)
+	if err != nil {
+		return nil, err
+	}
+	attribution, err := collectionmodels.LoadTeamAttribution(client, dbName, collections.StaffMember)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := collectionmodels.GetCompletedTasksByFilter(client, dbName, collections.CompletedTask, attribution.TaskFilter([]string{team}, rangeStart, rangeEnd))
+	if err != nil {
+		return nil, err
+	}
+	adjustments, err := collectionmodels.GetApprovedAdjustments(client, dbName, collections.ScoreAdjustment, []string{team}, collectionmodels.KindTeam, rangeStart, rangeEnd)
 	if err != nil {
 		return nil, err
 	}
 
-	var results []PerformancePointTotalWithTime
+	totals := map[string]*PerformancePointTotal{}
+	totalOf := func(email string) *PerformancePointTotal {
+		if totals[email] == nil {
+			totals[email] = &PerformancePointTotal{Identifier: email}
+		}
+		return totals[email]
+	}
+	for i := range tasks {
+		if slices.Contains(attribution.TeamsOf(&tasks[i]), team) {
+			addTaskPoints(totalOf(tasks[i].AssigneeID), &tasks[i], rules.levels, rules.tools)
+		}
+	}
+	for _, a := range adjustments {
+		total := totalOf(a.Email)
+		total.TotalAdjustmentPoint += a.Point
+		total.TotalPerformancePoint += a.Point
+	}
 
-	if isWeekly {
-		dateRanges := splitByMonday(startDate, endDate)
+	results := make([]PerformancePointTotal, 0, len(totals))
+	for _, total := range totals {
+		results = append(results, *total)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Identifier < results[j].Identifier })
+	return results, nil
+}
 
-		for _, dateRange := range dateRanges {
-			taskList, err := collectionmodels.GetCompletedTasksByDateRange(client, dbName, collectionName, isTeam, identifier, dateRange[0], dateRange[1])
-			if err != nil {
-				return nil, err
-			}
-			if len(taskList) == 0 {
+// computePoints adds the points of completed tasks to totals, for every bucket of an identifier not marked in skip.
+// Tasks are fetched with one query over the span of the buckets left to compute.
+func computePoints(client *mongo.Client, dbName string, collections collectionmodels.Collections, identifiers []string, kind collectionmodels.IdentifierKind, buckets [][2]time.Time, rules *scoringRules, totals map[string][]PerformancePointTotal, skip map[string][]bool) error {
+	var pending []string
+	first, last := len(buckets), -1
+	for _, id := range identifiers {
+		missing := false
+		for b := range buckets {
+			if skip[id] != nil && skip[id][b] {
 				continue
 			}
-			per := GetPerformancePointTotals(identifier, taskList, level, toolList)
-			res := PerformancePointTotalWithTime{
-				StartDate:             dateRange[0],
-				EndDate:               dateRange[1],
-				TotalPerformancePoint: per,
-			}
-			results = append(results, res)
+			missing = true
+			first, last = min(first, b), max(last, b)
+		}
+		if missing {
+			pending = append(pending, id)
 		}
-		return results, nil
 	}
+	if len(pending) == 0 {
+		return nil
+	}
+	rangeStart, rangeEnd := buckets[first][0], buckets[last][1]
 
-	tasks, err := collectionmodels.GetCompletedTasksByDateRange(client, dbName, collectionName, isTeam, identifier, startDate, endDate)
-	if err != nil {
-		return nil, err
+	var filter bson.M
+	var attribution *collectionmodels.TeamAttribution
+	switch kind {
+	case collectionmodels.KindTeam:
+		var err error
+		attribution, err = collectionmodels.LoadTeamAttribution(client, dbName, collections.StaffMember)
+		if err != nil {
+			return err
+		}
+		filter = attribution.TaskFilter(pending, rangeStart, rangeEnd)
+	case collectionmodels.KindProject:
+		// Identifiers that are not project IDs match no task and keep zero points
+		var projectIDs []int
+		for _, id := range pending {
+			if projectID, err := strconv.Atoi(id); err == nil {
+				projectIDs = append(projectIDs, projectID)
+			}
+		}
+		filter = bson.M{
+			"project_id": bson.M{"$in": projectIDs},
+			"done_date":  bson.M{"$gte": rangeStart, "$lte": rangeEnd},
+		}
+	default:
+		filter = bson.M{
+			"assignee_id": bson.M{"$in": pending},
+			"done_date":   bson.M{"$gte": rangeStart, "$lte": rangeEnd},
+		}
 	}
-	if len(tasks) == 0 {
-		return nil, nil
+	tasks, err := collectionmodels.GetCompletedTasksByFilter(client, dbName, collections.CompletedTask, filter)
+	if err != nil {
+		return err
 	}
 
-	per := GetPerformancePointTotals(identifier, tasks, level, toolList)
-	res := PerformancePointTotalWithTime{
-		StartDate:             startDate,
-		EndDate:               endDate,
-		TotalPerformancePoint: per,
+	bucketTaskPoints(tasks, buckets, func(task *collectionmodels.CompletedTask) []string {
+		switch kind {
+		case collectionmodels.KindTeam:
+			return attribution.TeamsOf(task)
+		case collectionmodels.KindProject:
+			return []string{strconv.Itoa(task.ProjectID)}
+		}
+		return []string{task.AssigneeID}
+	}, rules, totals, skip)
+	return nil
+}
+
+// bucketTaskPoints adds the points of each task to the bucket of its done date, in the totals of each of its owners
+// that is not marked in skip
+func bucketTaskPoints(tasks []collectionmodels.CompletedTask, buckets [][2]time.Time, owners func(task *collectionmodels.CompletedTask) []string, rules *scoringRules, totals map[string][]PerformancePointTotal, skip map[string][]bool) {
+	for i := range tasks {
+		task := &tasks[i]
+		// Buckets are consecutive, the task falls in the first one ending at or after its done date
+		b := sort.Search(len(buckets), func(j int) bool { return !buckets[j][1].Before(task.DoneDate) })
+		if b == len(buckets) || task.DoneDate.Before(buckets[b][0]) {
+			continue
+		}
+		for _, owner := range owners(task) {
+			t, ok := totals[owner]
+			if !ok || (skip[owner] != nil && skip[owner][b]) {
+				continue
+			}
+			addTaskPoints(&t[b], task, rules.levels, rules.tools)
+		}
 	}
-	results = append(results, res)
-	return results, nil
 }
 
 func GetPerformancePointTotals(identifier string, tasks []collectionmodels.CompletedTask, level []collectionmodels.Level, toolList []collectionmodels.CreativeTool) PerformancePointTotal {
 	performancePointTotal := PerformancePointTotal{}
 
-	for _, task := range tasks {
-		var TaskPoint = GetPointByLevel(level, task.Team, task.Level)
-		factor, sum := GetCreativeTaskFactor(toolList, task.Tool, task.Level, task.Team)
-		var BasePoint = float64(TaskPoint) * factor
-		var CreativeProcessPoint = sum
-		var CreativeTaskPoint = float64(TaskPoint) - BasePoint
-
-		var CreativePoint = CreativeTaskPoint + CreativeProcessPoint
-		var TotalPerformance = BasePoint + CreativePoint
-
-		performancePointTotal.TotalBasePoint += BasePoint
-		performancePointTotal.TotalCreativeProcessPoint += CreativeProcessPoint
-		performancePointTotal.TotalCreativeTaskPoint += CreativeTaskPoint
-		performancePointTotal.TotalPerformancePoint += TotalPerformance
+	for i := range tasks {
+		addTaskPoints(&performancePointTotal, &tasks[i], level, toolList)
 	}
 
 	performancePointTotal.Identifier = identifier
@@ -729,6 +917,46 @@ func GetPerformancePointTotals(identifier string, tasks []collectionmodels.Compl
 	return performancePointTotal
 }
 
+// addAdjustments adds the approved adjustments of identifiers to the bucket containing their week start
+func addAdjustments(client *mongo.Client, dbName string, collections collectionmodels.Collections, identifiers []string, kind collectionmodels.IdentifierKind, buckets [][2]time.Time, totals map[string][]PerformancePointTotal) error {
+	adjustments, err := collectionmodels.GetApprovedAdjustments(client, dbName, collections.ScoreAdjustment, identifiers, kind, buckets[0][0], buckets[len(buckets)-1][1])
+	if err != nil {
+		return err
+	}
+	for _, a := range adjustments {
+		b := sort.Search(len(buckets), func(j int) bool { return !buckets[j][1].Before(a.WeekStart) })
+		if b == len(buckets) || a.WeekStart.Before(buckets[b][0]) {
+			continue
+		}
+		owner := a.Email
+		if kind == collectionmodels.KindTeam {
+			owner = a.Team
+		}
+		if t, ok := totals[owner]; ok {
+			t[b].TotalAdjustmentPoint += a.Point
+			t[b].TotalPerformancePoint += a.Point
+		}
+	}
+	return nil
+}
+
+// addTaskPoints adds the base, creative and total points of a task to total
+func addTaskPoints(total *PerformancePointTotal, task *collectionmodels.CompletedTask, level []collectionmodels.Level, toolList []collectionmodels.CreativeTool) {
+	var TaskPoint = GetPointByLevel(level, task.Team, task.Level)
+	factor, sum := GetCreativeTaskFactor(toolList, task.Tool, task.Level, task.Team)
+	var BasePoint = float64(TaskPoint) * factor
+	var CreativeProcessPoint = sum
+	var CreativeTaskPoint = float64(TaskPoint) - BasePoint
+
+	var CreativePoint = CreativeTaskPoint + CreativeProcessPoint
+	var TotalPerformance = BasePoint + CreativePoint
+
+	total.TotalBasePoint += BasePoint
+	total.TotalCreativeProcessPoint += CreativeProcessPoint
+	total.TotalCreativeTaskPoint += CreativeTaskPoint
+	total.TotalPerformancePoint += TotalPerformance
+}
+
 func GetPointByLevel(levels []collectionmodels.Level, team string, level int) int {
 
 	for _, l := range levels {
@@ -779,45 +1007,3 @@ func GetCreativeTaskFactor(tools []collectionmodels.CreativeTool, inUsed []int,
 
 	return factor, sum
 }
-
-func splitByMonday(startDate, endDate time.Time) [][2]time.Time {
-	var ranges [][2]time.Time
-
-	// Normalize ngày (trunc về 00:00)
-	startDate = startDate.Truncate(24 * time.Hour)
-	endDate = endDate.Truncate(24 * time.Hour)
-
-	prev := startDate
-
-	// Tìm thứ Hai đầu tiên sau startDate
-	current := startDate
-	if current.Weekday() != time.Monday {
-		daysUntilMonday := (int(time.Monday) - int(current.Weekday()) + 7) % 7
-		if daysUntilMonday == 0 {
-			daysUntilMonday = 7
-		}
-		current = current.AddDate(0, 0, daysUntilMonday)
-	}
-
-	for current.Before(endDate) {
-		// Kết thúc là 23:59:59 thứ 2
-		endOfMonday := time.Date(current.Year(), current.Month(), current.Day(), 23, 59, 59, 0, current.Location())
-		ranges = append(ranges, [2]time.Time{prev, endOfMonday})
-		// Bắt đầu phần tử kế tiếp là 0:00:00 thứ 3
-		prev = endOfMonday.Add(time.Second)
-		current = current.AddDate(0, 0, 7)
-	}
-
-	// Đoạn cuối cùng kết thúc ở endDate
-	if prev.Before(endDate) || prev.Equal(endDate) {
-		// Nếu endDate là thứ 2 thì kết thúc là 23:59:59 endDate
-		if endDate.Weekday() == time.Monday {
-			endOfMonday := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 0, endDate.Location())
-			ranges = append(ranges, [2]time.Time{prev, endOfMonday})
-		} else {
-			ranges = append(ranges, [2]time.Time{prev, endDate})
-		}
-	}
-
-	return ranges
-}
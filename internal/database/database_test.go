@@ -0,0 +1,251 @@
+package db_handler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	benchMembers      = 40
+	benchTasksPerWeek = 10
+	benchWeeks        = 8
+	benchTeams        = 4
+)
+
+var benchCollections = collectionmodels.Collections{
+	CompletedTask:        "completed-task",
+	PerformanceSnapshot:  "performance-snapshots",
+	WeekClosure:          "week-closures",
+	ScoreAdjustment:      "score-adjustments",
+	ScoreDispute:         "score-disputes",
+	StaffMember:          "staff-member",
+	WeeklyTarget:         "weekly-targets",
+	WeeklyTargetOverride: "weekly-target-overrides",
+	MemberTarget:         "member-targets",
+	TargetWeight:         "target-weights",
+	Holiday:              "holidays",
+	MemberLeave:          "member-leaves",
+	ProjectDetail:        "project-detail",
+	Level:                "level",
+	WeeklyOrder:          "weekly-order",
+	AssetType:            "asset-types",
+	CreativeTools:        "creative-tools",
+	SchemaMigrations:     "schema-migrations",
+}
+
+// seedBenchDatabase fills a throwaway database with members, levels and tasks, and drops it when the benchmark ends.
+// It needs a MongoDB server, given by MONGO_URI, and skips the benchmark otherwise.
+func seedBenchDatabase(b *testing.B) (*mongo.Client, string, []string, time.Time, time.Time) {
+	b.Helper()
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		b.Skip("MONGO_URI is not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		b.Fatal(err)
+	}
+	dbName := fmt.Sprintf("performance-bench-%d", time.Now().UnixNano())
+	database := client.Database(dbName)
+	b.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		database.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+
+	startDate := calendar.StartOfWeek(time.Date(2025, 1, 7, 0, 0, 0, 0, calendar.Location()))
+	endDate := calendar.EndOfWeek(startDate.AddDate(0, 0, 7*(benchWeeks-1)))
+
+	var levels, tools, members, tasks []interface{}
+	for t := 0; t < benchTeams; t++ {
+		team := fmt.Sprintf("team-%d", t)
+		levels = append(levels, collectionmodels.Level{Team: team, LevelPoint: []int{1, 2, 4, 8, 16}})
+		tools = append(tools,
+			collectionmodels.CreativeTool{Team: team, ToolName: "task", Type: "t", Point: []float64{0.1, 0.1, 0.2, 0.2, 0.3}, Index: 1},
+			collectionmodels.CreativeTool{Team: team, ToolName: "process", Type: "p", Point: []float64{0.5}, Index: 2},
+		)
+	}
+	emails := make([]string, benchMembers)
+	for m := range emails {
+		emails[m] = fmt.Sprintf("member-%d@example.com", m)
+		team := fmt.Sprintf("team-%d", m%benchTeams)
+		members = append(members, collectionmodels.Member{
+			MemberID:    fmt.Sprint(m),
+			Email:       emails[m],
+			Assignments: []collectionmodels.TeamAssignment{{Team: team, Role: "member"}},
+		})
+		for w := 0; w < benchWeeks; w++ {
+			for i := 0; i < benchTasksPerWeek; i++ {
+				tasks = append(tasks, collectionmodels.CompletedTask{
+					TaskID:     fmt.Sprintf("%d-%d-%d", m, w, i),
+					AssigneeID: emails[m],
+					Level:      1 + i%5,
+					Tool:       []int{i % 3},
+					Team:       team,
+					DoneDate:   startDate.AddDate(0, 0, 7*w+i%7).Add(10 * time.Hour),
+				})
+			}
+		}
+	}
+	for collName, docs := range map[string][]interface{}{
+		benchCollections.Level:         levels,
+		benchCollections.CreativeTools: tools,
+		benchCollections.StaffMember:   members,
+		benchCollections.CompletedTask: tasks,
+	} {
+		if _, err := database.Collection(collName).InsertMany(ctx, docs); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return client, dbName, emails, startDate, endDate
+}
+
+// Sprints of 7 days from the first week give the weekly buckets of the per week queries without the performance
+// snapshots, so that every iteration computes the points from the tasks
+func benchGranularity(startDate time.Time) calendar.Granularity {
+	return calendar.Granularity{Unit: calendar.UnitSprint, SprintStart: startDate, SprintDays: 7}
+}
+
+// legacyWeekTotals is the point formula of the per week query path, kept to check bucketTaskPoints against it
+func legacyWeekTotals(tasks []collectionmodels.CompletedTask, levels []collectionmodels.Level, tools []collectionmodels.CreativeTool) PerformancePointTotal {
+	var total PerformancePointTotal
+	for _, task := range tasks {
+		taskPoint := GetPointByLevel(levels, task.Team, task.Level)
+		factor, sum := GetCreativeTaskFactor(tools, task.Tool, task.Level, task.Team)
+		basePoint := float64(taskPoint) * factor
+		creativeTaskPoint := float64(taskPoint) - basePoint
+		total.TotalBasePoint += basePoint
+		total.TotalCreativeProcessPoint += sum
+		total.TotalCreativeTaskPoint += creativeTaskPoint
+		total.TotalPerformancePoint += basePoint + creativeTaskPoint + sum
+	}
+	return total
+}
+
+// legacyPerformancePoints is the per week query path GetPerformancePointsBatch replaced: one Find per identifier and week
+func legacyPerformancePoints(client *mongo.Client, dbName string, collections collectionmodels.Collections, identifier string, startDate, endDate time.Time) ([]PerformancePointTotalWithTime, error) {
+	levels, err := collectionmodels.GetAllLevels(client, dbName, collections.Level, false)
+	if err != nil {
+		return nil, err
+	}
+	tools, err := collectionmodels.GetAllCreativeTools(client, dbName, collections.CreativeTools, false)
+	if err != nil {
+		return nil, err
+	}
+	var results []PerformancePointTotalWithTime
+	for _, week := range calendar.SplitWeeks(startDate, endDate) {
+		tasks, err := collectionmodels.GetCompletedTasksByDateRange(client, dbName, collections.CompletedTask, false, identifier, week[0], week[1])
+		if err != nil {
+			return nil, err
+		}
+		total := legacyWeekTotals(tasks, levels, tools)
+		total.Identifier = identifier
+		results = append(results, PerformancePointTotalWithTime{StartDate: week[0], EndDate: week[1], TotalPerformancePoint: total})
+	}
+	return results, nil
+}
+
+func BenchmarkPerformancePointsBatch(b *testing.B) {
+	client, dbName, emails, startDate, endDate := seedBenchDatabase(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetPerformancePointsBatch(client, dbName, benchCollections, emails, startDate, endDate, collectionmodels.KindMember, benchGranularity(startDate)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPerformancePointsPerWeekQueries(b *testing.B) {
+	client, dbName, emails, startDate, endDate := seedBenchDatabase(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, email := range emails {
+			if _, err := legacyPerformancePoints(client, dbName, benchCollections, email, startDate, endDate); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestBucketTaskPointsMatchesPerWeekTotals(t *testing.T) {
+	rules := &scoringRules{
+		levels: []collectionmodels.Level{
+			{Team: "Art", LevelPoint: []int{1, 2, 4, 8, 16}},
+			{Team: "Video", LevelPoint: []int{2, 3, 5, 8, 13}},
+		},
+		tools: []collectionmodels.CreativeTool{
+			{Team: "Art", Type: "t", Point: []float64{0.1, 0.1, 0.2, 0.2, 0.3}, Index: 1},
+			{Team: "Art", Type: "p", Point: []float64{0.5}, Index: 2},
+			{Team: "Video", Type: "t", Point: []float64{0.2, 0.2, 0.2, 0.3, 0.3}, Index: 1},
+		},
+	}
+	startDate := calendar.StartOfWeek(time.Date(2024, 12, 24, 0, 0, 0, 0, calendar.Location()))
+	endDate := calendar.EndOfWeek(startDate.AddDate(0, 0, 14))
+	weeks := calendar.SplitWeeks(startDate, endDate)
+
+	emails := []string{"a@example.com", "b@example.com"}
+	var tasks []collectionmodels.CompletedTask
+	for i := 0; i < 60; i++ {
+		tasks = append(tasks, collectionmodels.CompletedTask{
+			AssigneeID: emails[i%3%2],
+			Team:       []string{"Art", "Video"}[i%2],
+			Level:      1 + i%6,
+			Tool:       [][]int{{}, {1}, {2}, {1, 2}}[i%4],
+			DoneDate:   startDate.Add(time.Duration(i) * 9 * time.Hour),
+		})
+	}
+	// On the week boundaries, and just outside the range
+	tasks = append(tasks,
+		collectionmodels.CompletedTask{AssigneeID: emails[0], Team: "Art", Level: 3, DoneDate: weeks[0][1]},
+		collectionmodels.CompletedTask{AssigneeID: emails[1], Team: "Video", Level: 4, DoneDate: weeks[1][0]},
+		collectionmodels.CompletedTask{AssigneeID: emails[0], Team: "Art", Level: 2, DoneDate: startDate.Add(-time.Nanosecond)},
+		collectionmodels.CompletedTask{AssigneeID: emails[1], Team: "Art", Level: 2, DoneDate: endDate.Add(time.Nanosecond)},
+	)
+
+	totals := map[string][]PerformancePointTotal{}
+	for _, email := range emails {
+		totals[email] = make([]PerformancePointTotal, len(weeks))
+	}
+	assignee := func(task *collectionmodels.CompletedTask) []string { return []string{task.AssigneeID} }
+	bucketTaskPoints(tasks, weeks, assignee, rules, totals, nil)
+
+	const epsilon = 1e-9
+	for _, email := range emails {
+		for w, week := range weeks {
+			// The per week query: the tasks of the assignee done in the week, bounds included
+			var inWeek []collectionmodels.CompletedTask
+			for _, task := range tasks {
+				if task.AssigneeID == email && !task.DoneDate.Before(week[0]) && !task.DoneDate.After(week[1]) {
+					inWeek = append(inWeek, task)
+				}
+			}
+			want := legacyWeekTotals(inWeek, rules.levels, rules.tools)
+			got := totals[email][w]
+			for _, c := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"performance", got.TotalPerformancePoint, want.TotalPerformancePoint},
+				{"base", got.TotalBasePoint, want.TotalBasePoint},
+				{"creative process", got.TotalCreativeProcessPoint, want.TotalCreativeProcessPoint},
+				{"creative task", got.TotalCreativeTaskPoint, want.TotalCreativeTaskPoint},
+			} {
+				if math.Abs(c.got-c.want) > epsilon {
+					t.Errorf("%s week of %s: %s points = %v, want %v", email, week[0].Format("2006-01-02"), c.name, c.got, c.want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package db_handler
+
+import (
+	"errors"
+	"log"
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrInvalidScoring = errors.New("invalid scoring, the level or a tool is not defined for the team")
+
+// ValidateTaskScoring checks the level and tool indexes exist in the scoring config of the team
+func ValidateTaskScoring(client *mongo.Client, dbName, team string, level int, tools []int) error {
+	rules, err := loadScoringRules(client, dbName, collections)
+	if err != nil {
+		return err
+	}
+	levelCount := 0
+	for _, l := range rules.levels {
+		if l.Team == team {
+			levelCount = len(l.LevelPoint)
+		}
+	}
+	if level < 1 || level > levelCount {
+		return ErrInvalidScoring
+	}
+	for _, idx := range tools {
+		found := false
+		for _, t := range rules.tools {
+			if t.Team == team && t.Index == idx {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrInvalidScoring
+		}
+	}
+	return nil
+}
+
+// ResolveScoreDispute accepts or rejects an open dispute. Accepting gives the task the proposed level and tools
+// and drops the unlocked snapshots of its week, it fails with ErrWeekClosed once the week is closed for the team
+// or the member. The dispute is claimed before the task is rescored, so that of two concurrent resolutions only
+// one rescores it, and it is reopened when rescoring fails.
+func ResolveScoreDispute(client *mongo.Client, dbName string, id primitive.ObjectID, accept bool, resolvedBy, resolution string) (*collectionmodels.ScoreDispute, error) {
+	disputeColl := collections.ScoreDispute
+	dispute, err := collectionmodels.FindByID[collectionmodels.ScoreDispute](client, dbName, disputeColl, id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status != collectionmodels.DisputeOpen {
+		return nil, collectionmodels.ErrDisputeResolved
+	}
+
+	if accept {
+		closureColl := collections.WeekClosure
+		if err := collectionmodels.CheckTeamWeeksOpen(client, dbName, closureColl, []string{dispute.Team}, dispute.DoneDate, &dispute.DoneDate); err != nil {
+			return nil, err
+		}
+		if err := collectionmodels.CheckMemberWeeksOpen(client, dbName, closureColl, dispute.Email, dispute.DoneDate, &dispute.DoneDate); err != nil {
+			return nil, err
+		}
+		if err := ValidateTaskScoring(client, dbName, dispute.Team, dispute.ProposedLevel, dispute.ProposedTools); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := collectionmodels.ResolveScoreDispute(client, dbName, disputeColl, id, accept, resolvedBy, resolution); err != nil {
+		return nil, err
+	}
+	if accept {
+		if err := rescoreDisputedTask(client, dbName, dispute); err != nil {
+			if reopenErr := collectionmodels.ReopenScoreDispute(client, dbName, disputeColl, id); reopenErr != nil {
+				log.Println("Error reopening score dispute", id.Hex(), ":", reopenErr)
+			}
+			return nil, err
+		}
+	}
+	return collectionmodels.FindByID[collectionmodels.ScoreDispute](client, dbName, disputeColl, id)
+}
+
+// rescoreDisputedTask gives the task the proposed level and tools and drops the unlocked snapshots of its week
+func rescoreDisputedTask(client *mongo.Client, dbName string, dispute *collectionmodels.ScoreDispute) error {
+	if err := collectionmodels.UpdateCompletedTaskScoring(client, dbName, collections.CompletedTask, dispute.TaskID, dispute.ProposedLevel, dispute.ProposedTools); err != nil {
+		return err
+	}
+	weekEnd := calendar.EndOfWeek(dispute.DoneDate)
+	_, err := collectionmodels.DeletePerformanceSnapshots(client, dbName, collections.PerformanceSnapshot, calendar.StartOfWeek(dispute.DoneDate), &weekEnd, "")
+	return err
+}
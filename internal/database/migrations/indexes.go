@@ -0,0 +1,150 @@
+package migrations
+
+import (
+	"context"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type collectionIndexes struct {
+	collection string
+	models     []mongo.IndexModel
+}
+
+func indexes(collections collectionmodels.Collections) []collectionIndexes {
+	return []collectionIndexes{
+		{
+			collection: collections.StaffMember,
+			models: []mongo.IndexModel{
+				// One document per person, their teams live in the assignments array
+				{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetName("uniq_email").SetUnique(true)},
+				{Keys: bson.D{{Key: "assignments.team", Value: 1}}, Options: options.Index().SetName("assignments_team")},
+			},
+		},
+		{
+			collection: collections.WeeklyOrder,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "start_week", Value: 1}}, Options: options.Index().SetName("uniq_project_id_start_week").SetUnique(true)},
+				{Keys: bson.D{{Key: "start_week", Value: 1}}, Options: options.Index().SetName("start_week")},
+			},
+		},
+		{
+			collection: collections.AssetType,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetName("uniq_key").SetUnique(true)},
+			},
+		},
+		{
+			collection: collections.Level,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "team", Value: 1}}, Options: options.Index().SetName("uniq_team").SetUnique(true)},
+			},
+		},
+		{
+			collection: collections.CreativeTools,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "team", Value: 1}, {Key: "tool_name", Value: 1}}, Options: options.Index().SetName("uniq_team_tool_name").SetUnique(true)},
+			},
+		},
+		{
+			collection: collections.CompletedTask,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "done_date", Value: 1}}, Options: options.Index().SetName("done_date")},
+				{Keys: bson.D{{Key: "assignee_id", Value: 1}, {Key: "done_date", Value: 1}}, Options: options.Index().SetName("assignee_id_done_date")},
+				{Keys: bson.D{{Key: "team", Value: 1}, {Key: "done_date", Value: 1}}, Options: options.Index().SetName("team_done_date")},
+				{Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "done_date", Value: 1}}, Options: options.Index().SetName("project_id_done_date")},
+			},
+		},
+		{
+			collection: collections.PerformanceSnapshot,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "identifier", Value: 1}, {Key: "kind", Value: 1}, {Key: "week_start", Value: 1}}, Options: options.Index().SetName("uniq_identifier_kind_week_start").SetUnique(true)},
+				{Keys: bson.D{{Key: "week_start", Value: 1}}, Options: options.Index().SetName("week_start")},
+			},
+		},
+		{
+			collection: collections.WeekClosure,
+			models: []mongo.IndexModel{
+				// Reopened weeks stay as soft deleted history, each with its own deleted_at, while a team has at most one
+				// active closure per week: active closures have no deleted_at, indexed as null
+				{Keys: bson.D{{Key: "team", Value: 1}, {Key: "week_start", Value: 1}, {Key: "deleted_at", Value: 1}}, Options: options.Index().SetName("uniq_team_week_start_deleted_at").SetUnique(true)},
+				{Keys: bson.D{{Key: "members", Value: 1}, {Key: "week_start", Value: 1}}, Options: options.Index().SetName("members_week_start")},
+			},
+		},
+		{
+			collection: collections.ScoreAdjustment,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "email", Value: 1}, {Key: "week_start", Value: 1}}, Options: options.Index().SetName("email_week_start")},
+				{Keys: bson.D{{Key: "team", Value: 1}, {Key: "week_start", Value: 1}}, Options: options.Index().SetName("team_week_start")},
+				{Keys: bson.D{{Key: "status", Value: 1}}, Options: options.Index().SetName("status")},
+			},
+		},
+		{
+			collection: collections.ScoreDispute,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "status", Value: 1}}, Options: options.Index().SetName("task_id_status")},
+				{Keys: bson.D{{Key: "team", Value: 1}, {Key: "status", Value: 1}}, Options: options.Index().SetName("team_status")},
+				{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetName("email")},
+			},
+		},
+		{
+			collection: collections.WeeklyTarget,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "team", Value: 1}, {Key: "date_from", Value: 1}}, Options: options.Index().SetName("team_date_from")},
+			},
+		},
+		{
+			collection: collections.WeeklyTargetOverride,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "team", Value: 1}, {Key: "week_start", Value: 1}}, Options: options.Index().SetName("uniq_team_week_start").SetUnique(true)},
+			},
+		},
+		{
+			collection: collections.Holiday,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "date_from", Value: 1}}, Options: options.Index().SetName("date_from")},
+				{Keys: bson.D{{Key: "ics_uid", Value: 1}}, Options: options.Index().SetName("ics_uid").SetSparse(true)},
+			},
+		},
+		{
+			collection: collections.MemberLeave,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "email", Value: 1}, {Key: "date_from", Value: 1}}, Options: options.Index().SetName("email_date_from")},
+			},
+		},
+		{
+			collection: collections.MemberTarget,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "email", Value: 1}, {Key: "date_from", Value: 1}}, Options: options.Index().SetName("email_date_from")},
+			},
+		},
+		{
+			collection: collections.TargetWeight,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "team", Value: 1}}, Options: options.Index().SetName("uniq_team").SetUnique(true)},
+			},
+		},
+		{
+			collection: collections.ProjectDetail,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetName("uniq_id").SetUnique(true)},
+				{Keys: bson.D{{Key: "project", Value: 1}}, Options: options.Index().SetName("project")},
+				{Keys: bson.D{{Key: "aliases", Value: 1}}, Options: options.Index().SetName("aliases")},
+				{Keys: bson.D{{Key: "assignments.email", Value: 1}}, Options: options.Index().SetName("assignments_email")},
+			},
+		},
+	}
+}
+
+// EnsureIndexes creates every required index. CreateMany is a no-op for indexes that already exist.
+func EnsureIndexes(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+	for _, ci := range indexes(collections) {
+		if _, err := database.Collection(ci.collection).Indexes().CreateMany(ctx, ci.models); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package migrations
+
+import (
+	"context"
+	"log"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mergeMembersByEmail folds every member document of an email into the newest one.
+// Assignments of all documents are kept so the team history is preserved, the older
+// documents are moved to "<collection>-duplicates", and the per document team/role
+// fields are dropped in favour of the assignments.
+func mergeMembersByEmail(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+	collName := collections.StaffMember
+	collection := database.Collection(collName)
+	backup := database.Collection(collName + "-duplicates")
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: -1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$email"},
+			{Key: "docs", Value: bson.D{{Key: "$push", Value: "$$ROOT"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var groups []struct {
+		Docs []bson.M `bson:"docs"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		keep := g.Docs[0]
+		assignments := bson.A{}
+		var staleIDs bson.A
+		for i, d := range g.Docs {
+			if a, ok := d["assignments"].(bson.A); ok {
+				assignments = append(assignments, a...)
+			}
+			if i > 0 {
+				staleIDs = append(staleIDs, d["_id"])
+				if _, err := backup.InsertOne(ctx, d); err != nil {
+					return err
+				}
+			}
+		}
+		if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": staleIDs}}); err != nil {
+			return err
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": keep["_id"]}, bson.M{"$set": bson.M{"assignments": assignments}}); err != nil {
+			return err
+		}
+		log.Printf("Merged %d member documents of %v", len(g.Docs), keep["email"])
+	}
+
+	if _, err := collection.UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"team": "", "role": ""}}); err != nil {
+		return err
+	}
+
+	// The per team unique index is replaced by a unique email index in EnsureIndexes
+	if _, err := collection.Indexes().DropOne(ctx, "uniq_email_team"); err != nil {
+		if cmdErr, ok := err.(mongo.CommandError); !ok || (cmdErr.Name != "IndexNotFound" && cmdErr.Name != "NamespaceNotFound") {
+			return err
+		}
+	}
+	return nil
+}
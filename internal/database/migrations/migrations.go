@@ -0,0 +1,246 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migration is a one-off data change. Applied versions are recorded in the schema migrations
+// collection so each migration runs exactly once per database.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error
+}
+
+type appliedMigration struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// registry lists every data migration. Append new ones with the next version number, never reorder.
+var registry = []Migration{
+	{
+		Version:     1,
+		Description: "remove duplicate level documents per team",
+		Up: func(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+			return moveDuplicates(ctx, database, collections.Level, "team")
+		},
+	},
+	{
+		Version:     2,
+		Description: "remove duplicate weekly orders per project and start week",
+		Up: func(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+			return moveDuplicates(ctx, database, collections.WeeklyOrder, "project", "start_week")
+		},
+	},
+	{
+		Version:     3,
+		Description: "remove duplicate members per email and team",
+		Up: func(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+			return moveDuplicates(ctx, database, collections.StaffMember, "email", "team")
+		},
+	},
+	{
+		Version:     4,
+		Description: "remove duplicate creative tools per team and tool name",
+		Up: func(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+			return moveDuplicates(ctx, database, collections.CreativeTools, "team", "tool_name")
+		},
+	},
+	{
+		Version:     5,
+		Description: "start member assignment history from the current team and role",
+		Up: func(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+			collection := database.Collection(collections.StaffMember)
+			_, err := collection.UpdateMany(ctx,
+				bson.M{"assignments": bson.M{"$exists": false}},
+				mongo.Pipeline{
+					{{Key: "$set", Value: bson.D{
+						{Key: "assignments", Value: bson.A{bson.D{
+							{Key: "team", Value: "$team"},
+							{Key: "role", Value: "$role"},
+							{Key: "from", Value: time.Time{}},
+						}}},
+					}}},
+				},
+			)
+			return err
+		},
+	},
+	{
+		Version:     6,
+		Description: "merge member documents sharing an email into one document with several memberships",
+		Up:          mergeMembersByEmail,
+	},
+	{
+		Version:     7,
+		Description: "replace the fixed weekly order columns with line items of an asset type catalog",
+		Up:          moveOrderColumnsToItems,
+	},
+	{
+		Version:     8,
+		Description: "replace the per team assignee columns of project details with assignments of members",
+		Up:          moveProjectColumnsToAssignments,
+	},
+	{
+		Version:     9,
+		Description: "key projects by ID with aliases and link weekly orders and completed tasks to it",
+		Up:          linkProjectsByID,
+	},
+	{
+		Version:     10,
+		Description: "replace the is_team flag of performance snapshots with the kind of their identifier",
+		Up: func(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+			collection := database.Collection(collections.PerformanceSnapshot)
+			for kind, isTeam := range map[collectionmodels.IdentifierKind]bool{collectionmodels.KindMember: false, collectionmodels.KindTeam: true} {
+				_, err := collection.UpdateMany(ctx,
+					bson.M{"is_team": isTeam},
+					bson.M{"$set": bson.M{"kind": kind}, "$unset": bson.M{"is_team": ""}})
+				if err != nil {
+					return err
+				}
+			}
+			// The unique index moves from is_team to kind in EnsureIndexes
+			if _, err := collection.Indexes().DropOne(ctx, "uniq_identifier_is_team_week_start"); err != nil {
+				if cmdErr, ok := err.(mongo.CommandError); !ok || (cmdErr.Name != "IndexNotFound" && cmdErr.Name != "NamespaceNotFound") {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     11,
+		Description: "remove duplicate active week closures per team and week",
+		Up: func(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+			if err := moveDuplicates(ctx, database, collections.WeekClosure, "team", "week_start", "deleted_at"); err != nil {
+				return err
+			}
+			// Replaced by the unique index including deleted_at in EnsureIndexes
+			if _, err := database.Collection(collections.WeekClosure).Indexes().DropOne(ctx, "team_week_start"); err != nil {
+				if cmdErr, ok := err.(mongo.CommandError); !ok || (cmdErr.Name != "IndexNotFound" && cmdErr.Name != "NamespaceNotFound") {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// Run applies pending data migrations in version order, then makes sure every index exists.
+// Migrations run first so that unique indexes can be built on deduplicated data.
+func Run(client *mongo.Client, dbName string, collections collectionmodels.Collections) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	database := client.Database(dbName)
+	migrationColl := database.Collection(collections.SchemaMigrations)
+
+	applied := map[int]bool{}
+	cursor, err := migrationColl.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var done []appliedMigration
+	if err := cursor.All(ctx, &done); err != nil {
+		return err
+	}
+	for _, m := range done {
+		applied[m.Version] = true
+	}
+
+	pending := make([]Migration, len(registry))
+	copy(pending, registry)
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+
+	for _, m := range pending {
+		if applied[m.Version] {
+			continue
+		}
+		log.Printf("Applying migration %d: %s", m.Version, m.Description)
+		if err := m.Up(ctx, database, collections); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		_, err := migrationColl.InsertOne(ctx, appliedMigration{
+			Version:     m.Version,
+			Description: m.Description,
+			AppliedAt:   time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return EnsureIndexes(ctx, database, collections)
+}
+
+// moveDuplicates keeps the newest document for each combination of keys and moves the
+// older ones to "<collection>-duplicates" so nothing is lost.
+func moveDuplicates(ctx context.Context, database *mongo.Database, collName string, keys ...string) error {
+	collection := database.Collection(collName)
+	backup := database.Collection(collName + "-duplicates")
+
+	groupID := bson.D{}
+	for _, k := range keys {
+		groupID = append(groupID, bson.E{Key: k, Value: "$" + k})
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: -1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: groupID},
+			{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var groups []struct {
+		IDs []interface{} `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+
+	moved := 0
+	for _, g := range groups {
+		// ids are sorted newest first, keep the first one
+		stale := bson.M{"_id": bson.M{"$in": g.IDs[1:]}}
+		staleCursor, err := collection.Find(ctx, stale)
+		if err != nil {
+			return err
+		}
+		var docs []bson.M
+		if err := staleCursor.All(ctx, &docs); err != nil {
+			return err
+		}
+		var toInsert []interface{}
+		for _, d := range docs {
+			toInsert = append(toInsert, d)
+		}
+		if len(toInsert) > 0 {
+			if _, err := backup.InsertMany(ctx, toInsert); err != nil {
+				return err
+			}
+		}
+		res, err := collection.DeleteMany(ctx, stale)
+		if err != nil {
+			return err
+		}
+		moved += int(res.DeletedCount)
+	}
+	if moved > 0 {
+		log.Printf("Moved %d duplicate documents from %s to %s", moved, collName, backup.Name())
+	}
+	return nil
+}
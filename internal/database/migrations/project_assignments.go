@@ -0,0 +1,92 @@
+package migrations
+
+import (
+	"context"
+	"log"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/database/constants"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// legacyProjectColumns are the fixed per team assignee columns of a project detail
+var legacyProjectColumns = []struct {
+	column, team string
+}{
+	{"research", constants.Research},
+	{"art", constants.Art},
+	{"concept", constants.Concept},
+	{"video", constants.Video},
+	{"pla", constants.Playable},
+	{"ua", constants.UA},
+}
+
+// moveProjectColumnsToAssignments turns the assignee columns of every project detail into ongoing owner
+// assignments. A column holds a member's email or name, values matching no member are moved to
+// "<collection>-unresolved-assignees" so nothing is lost.
+func moveProjectColumnsToAssignments(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+	cursor, err := database.Collection(collections.StaffMember).Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var members []collectionmodels.Member
+	if err := cursor.All(ctx, &members); err != nil {
+		return err
+	}
+	emails := map[string]string{}
+	for _, m := range members {
+		emails[strings.ToLower(m.Name)] = m.Email
+	}
+	// Emails win over a name that happens to be someone's email
+	for _, m := range members {
+		emails[strings.ToLower(m.Email)] = m.Email
+	}
+
+	collection := database.Collection(collections.ProjectDetail)
+	backup := database.Collection(collections.ProjectDetail + "-unresolved-assignees")
+	cursor, err = collection.Find(ctx, bson.M{"assignments": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	var projects []bson.M
+	if err := cursor.All(ctx, &projects); err != nil {
+		return err
+	}
+
+	unset := bson.M{}
+	for _, c := range legacyProjectColumns {
+		unset[c.column] = ""
+	}
+	for _, p := range projects {
+		assignments := []collectionmodels.ProjectAssignment{}
+		for _, c := range legacyProjectColumns {
+			value, _ := p[c.column].(string)
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			email, ok := emails[strings.ToLower(value)]
+			if !ok {
+				log.Printf("Project %v: %s assignee %q matches no member", p["project"], c.team, value)
+				if _, err := backup.InsertOne(ctx, bson.M{"project": p["project"], "team": c.team, "value": value}); err != nil {
+					return err
+				}
+				continue
+			}
+			assignments = append(assignments, collectionmodels.ProjectAssignment{
+				Email: email,
+				Team:  c.team,
+				Role:  collectionmodels.ProjectRoleOwner,
+				From:  time.Time{},
+			})
+		}
+		_, err := collection.UpdateOne(ctx, bson.M{"_id": p["_id"]}, bson.M{"$set": bson.M{"assignments": assignments}, "$unset": unset})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package migrations
+
+import (
+	"context"
+	"log"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// linkProjectsByID makes project details a catalog keyed by their integer ID and links orders and tasks to it.
+// Projects without an ID, or sharing one, are given the next free ID. Project names of orders missing from
+// the catalog are added to it. Tasks are linked when their name matches a project, the others are left to
+// resolve once the project or an alias is added. Orders of the same project and week, placed under several of its
+// names, are then merged into the newest one and the others moved aside.
+func linkProjectsByID(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+	projects := database.Collection(collections.ProjectDetail)
+	cursor, err := projects.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	var details []collectionmodels.ProjectDetail
+	if err := cursor.All(ctx, &details); err != nil {
+		return err
+	}
+
+	nextID := 1
+	for _, d := range details {
+		nextID = max(nextID, d.ProjectID+1)
+	}
+	used := map[int]bool{}
+	for i := range details {
+		d := &details[i]
+		set := bson.M{}
+		if d.ProjectID <= 0 || used[d.ProjectID] {
+			d.ProjectID = nextID
+			nextID++
+			set["id"] = d.ProjectID
+		}
+		used[d.ProjectID] = true
+		if d.Aliases == nil {
+			set["aliases"] = bson.A{}
+		}
+		if len(set) > 0 {
+			if _, err := projects.UpdateOne(ctx, bson.M{"_id": d.ID}, bson.M{"$set": set}); err != nil {
+				return err
+			}
+		}
+	}
+	catalog := collectionmodels.NewProjectCatalog(details)
+
+	orders := database.Collection(collections.WeeklyOrder)
+	names, err := orders.Distinct(ctx, "project", bson.M{})
+	if err != nil {
+		return err
+	}
+	for _, n := range names {
+		name, _ := n.(string)
+		if name == "" {
+			continue
+		}
+		id, ok := catalog.Resolve(name)
+		if !ok {
+			id = catalog.Reserve(name)
+			_, err := projects.InsertOne(ctx, collectionmodels.ProjectDetail{
+				ProjectID:   id,
+				Project:     name,
+				Aliases:     []string{},
+				Assignments: []collectionmodels.ProjectAssignment{},
+			})
+			if err != nil {
+				return err
+			}
+			log.Printf("Added project %q of weekly orders to the catalog as %d", name, id)
+		}
+		_, err := orders.UpdateMany(ctx, bson.M{"project": name}, bson.M{"$set": bson.M{"project_id": id, "project": catalog.Name(id)}})
+		if err != nil {
+			return err
+		}
+	}
+
+	tasks := database.Collection(collections.CompletedTask)
+	names, err = tasks.Distinct(ctx, "project", bson.M{"project_id": nil})
+	if err != nil {
+		return err
+	}
+	unknown := 0
+	for _, n := range names {
+		name, _ := n.(string)
+		id, ok := catalog.Resolve(name)
+		if !ok {
+			if name != "" {
+				unknown++
+			}
+			continue
+		}
+		if _, err := tasks.UpdateMany(ctx, bson.M{"project": name}, bson.M{"$set": bson.M{"project_id": id}}); err != nil {
+			return err
+		}
+	}
+	if unknown > 0 {
+		log.Printf("%d project names of completed tasks match no project of the catalog", unknown)
+	}
+
+	// The unique index moves from the project name to its ID in EnsureIndexes
+	if err := mergeCollidingOrders(ctx, orders); err != nil {
+		return err
+	}
+	if err := moveDuplicates(ctx, database, collections.WeeklyOrder, "project_id", "start_week"); err != nil {
+		return err
+	}
+	if _, err := orders.Indexes().DropOne(ctx, "uniq_project_start_week"); err != nil {
+		if cmdErr, ok := err.(mongo.CommandError); !ok || (cmdErr.Name != "IndexNotFound" && cmdErr.Name != "NamespaceNotFound") {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeCollidingOrders adds the items of the active orders sharing a project and week with a newer order to the
+// newest one, which moveDuplicates keeps. Items asking the same team for the same asset type keep the newest
+// quantity. Each merged order is logged, its document is kept in the "-duplicates" collection by moveDuplicates.
+func mergeCollidingOrders(ctx context.Context, orders *mongo.Collection) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "project_id", Value: "$project_id"}, {Key: "start_week", Value: "$start_week"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+	}
+	cursor, err := orders.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var groups []struct {
+		Key bson.M `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		groupCursor, err := orders.Find(ctx, g.Key, options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}))
+		if err != nil {
+			return err
+		}
+		var group []collectionmodels.WeeklyOrder
+		if err := groupCursor.All(ctx, &group); err != nil {
+			return err
+		}
+		if len(group) < 2 {
+			continue
+		}
+		kept := &group[0]
+		items := kept.Items
+		active := kept.DeletedAt == nil
+		for _, order := range group[1:] {
+			if order.DeletedAt != nil {
+				log.Printf("Dropping deleted order %s of project %d for the week of %s, order %s is kept",
+					order.ID.Hex(), order.ProjectID, order.StartWeek.Format("2006-01-02"), kept.ID.Hex())
+				continue
+			}
+			active = true
+			added := 0
+			for _, item := range order.Items {
+				if !slices.ContainsFunc(items, func(i collectionmodels.OrderItem) bool { return i.AssetType == item.AssetType && i.Team == item.Team }) {
+					items = append(items, item)
+					added++
+				}
+			}
+			log.Printf("Merging order %s of project %d (%q) for the week of %s into order %s: %d of its %d items added",
+				order.ID.Hex(), order.ProjectID, order.Project, order.StartWeek.Format("2006-01-02"), kept.ID.Hex(), added, len(order.Items))
+		}
+		if items == nil {
+			items = []collectionmodels.OrderItem{}
+		}
+		update := bson.M{"$set": bson.M{"items": items}}
+		if active && kept.DeletedAt != nil {
+			// The newest order was deleted but an older one still stands, the merged order stands too
+			update["$unset"] = bson.M{"deleted_at": "", "deleted_by": ""}
+		}
+		if _, err := orders.UpdateOne(ctx, bson.M{"_id": kept.ID}, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"context"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/database/constants"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// legacyOrderColumns are the fixed weekly order columns, each became an asset type of the catalog
+var legacyOrderColumns = []struct {
+	column, name, team, keyword string
+}{
+	{"art_cpp", "Art CPP", constants.Art, "cpp"},
+	{"art_icon", "Art Icon", constants.Art, "icon"},
+	{"art_banner", "Art Banner", constants.Art, "banner"},
+	{"playable", "Playable", constants.Playable, ""},
+	{"video", "Video", constants.Video, ""},
+}
+
+// moveOrderColumnsToItems seeds the asset type catalog with the fixed weekly order columns, keyed by the
+// column name, then turns the columns of every order into line items. Columns with a zero quantity are dropped.
+func moveOrderColumnsToItems(ctx context.Context, database *mongo.Database, collections collectionmodels.Collections) error {
+	catalog := database.Collection(collections.AssetType)
+	items := bson.A{}
+	unset := bson.A{}
+	for _, c := range legacyOrderColumns {
+		_, err := catalog.UpdateOne(ctx,
+			bson.M{"key": c.column},
+			bson.M{"$setOnInsert": bson.M{"key": c.column, "name": c.name, "team": c.team, "keyword": c.keyword}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+		items = append(items, bson.D{
+			{Key: "asset_type", Value: c.column},
+			{Key: "team", Value: c.team},
+			{Key: "quantity", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$" + c.column, 0}}}},
+			{Key: "priority", Value: "normal"},
+		})
+		unset = append(unset, c.column)
+	}
+
+	orders := database.Collection(collections.WeeklyOrder)
+	_, err := orders.UpdateMany(ctx,
+		bson.M{"items": bson.M{"$exists": false}},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.D{
+				{Key: "items", Value: bson.D{{Key: "$filter", Value: bson.D{
+					{Key: "input", Value: items},
+					{Key: "as", Value: "item"},
+					{Key: "cond", Value: bson.D{{Key: "$gt", Value: bson.A{"$$item.quantity", 0}}}},
+				}}}},
+			}}},
+			{{Key: "$unset", Value: unset}},
+		},
+	)
+	return err
+}
@@ -0,0 +1,91 @@
+package db_handler
+
+import (
+	"fmt"
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ResolveTaskProjects links the completed tasks of unknown projects whose name the catalog now knows,
+// to call after a project or an alias was added. It returns the number of tasks resolved.
+// The project snapshots of their weeks were built without them and are dropped, to be computed again.
+func ResolveTaskProjects(client *mongo.Client, dbName string) (int64, error) {
+	catalog, err := collectionmodels.LoadProjectCatalog(client, dbName, collections.ProjectDetail)
+	if err != nil {
+		return 0, err
+	}
+	resolved, err := collectionmodels.ResolveTaskProjects(client, dbName, collections.CompletedTask, catalog)
+	if err != nil || resolved.Count == 0 {
+		return 0, err
+	}
+	lastWeek := calendar.StartOfWeek(resolved.DoneTo)
+	if _, err := collectionmodels.DeletePerformanceSnapshots(client, dbName, collections.PerformanceSnapshot, calendar.StartOfWeek(resolved.DoneFrom), &lastWeek, collectionmodels.KindProject); err != nil {
+		return resolved.Count, err
+	}
+	return resolved.Count, nil
+}
+
+// RenameProject renames a project of the catalog, keeping the former name as an alias, and shows the new name
+// on its orders
+func RenameProject(client *mongo.Client, dbName string, projectDetail *collectionmodels.ProjectDetail) error {
+	if err := collectionmodels.UpdateProjectDetailToDatabase(client, dbName, collections.ProjectDetail, projectDetail); err != nil {
+		return err
+	}
+	return collectionmodels.RenameOrderProject(client, dbName, collections.WeeklyOrder, projectDetail.ProjectID, projectDetail.Project)
+}
+
+// ResolveOrderProject sets the catalog project of an order from its project name, and its name to the canonical one
+func ResolveOrderProject(client *mongo.Client, dbName string, order *collectionmodels.WeeklyOrder) error {
+	catalog, err := collectionmodels.LoadProjectCatalog(client, dbName, collections.ProjectDetail)
+	if err != nil {
+		return err
+	}
+	id, ok := catalog.Resolve(order.Project)
+	if !ok {
+		return collectionmodels.ErrUnknownProject
+	}
+	order.ProjectID = id
+	order.Project = catalog.Name(id)
+	return nil
+}
+
+// ResolveProjectID returns the catalog ID of a project given by name, alias or ID
+func ResolveProjectID(client *mongo.Client, dbName, project string) (int, error) {
+	catalog, err := collectionmodels.LoadProjectCatalog(client, dbName, collections.ProjectDetail)
+	if err != nil {
+		return 0, err
+	}
+	return resolveProject(catalog, project)
+}
+
+// ProjectIdentifiers returns the performance identifier, the decimal catalog ID, of each project given by name,
+// alias or ID
+func ProjectIdentifiers(client *mongo.Client, dbName string, projects []string) ([]string, error) {
+	catalog, err := collectionmodels.LoadProjectCatalog(client, dbName, collections.ProjectDetail)
+	if err != nil {
+		return nil, err
+	}
+	identifiers := make([]string, len(projects))
+	for i, project := range projects {
+		id, err := resolveProject(catalog, project)
+		if err != nil {
+			return nil, err
+		}
+		identifiers[i] = strconv.Itoa(id)
+	}
+	return identifiers, nil
+}
+
+// resolveProject returns the ID of a project given by name, alias or ID
+func resolveProject(catalog *collectionmodels.ProjectCatalog, project string) (int, error) {
+	if id, ok := catalog.Resolve(project); ok {
+		return id, nil
+	}
+	if n, err := strconv.Atoi(project); err == nil && catalog.Name(n) != "" {
+		return n, nil
+	}
+	return 0, fmt.Errorf("%w: %s", collectionmodels.ErrUnknownProject, project)
+}
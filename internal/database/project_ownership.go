@@ -0,0 +1,37 @@
+package db_handler
+
+import (
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ProjectWeekOwnership lists the members assigned to a project during one week
+type ProjectWeekOwnership struct {
+	WeekStart   time.Time                            `bson:"week_start"`
+	Assignments []collectionmodels.ProjectAssignment `bson:"assignments"`
+}
+
+// GetProjectOwnership returns who was assigned to a project, given by name, alias or ID, each week from startDate
+// to endDate, for team only when it is not empty. Deleted projects keep their history.
+func GetProjectOwnership(client *mongo.Client, dbName, project, team string, startDate, endDate time.Time) ([]ProjectWeekOwnership, error) {
+	projectID, err := ResolveProjectID(client, dbName, project)
+	if err != nil {
+		return nil, err
+	}
+	detail, err := collectionmodels.GetProjectDetail(client, dbName, collections.ProjectDetail, projectID)
+	if err != nil {
+		return nil, err
+	}
+	weeks := calendar.Weekly.Buckets(calendar.StartOfWeek(startDate), calendar.EndOfWeek(endDate))
+	history := make([]ProjectWeekOwnership, 0, len(weeks))
+	for _, week := range weeks {
+		history = append(history, ProjectWeekOwnership{
+			WeekStart:   week[0],
+			Assignments: detail.AssignmentsOf(team, week[0], week[1]),
+		})
+	}
+	return history, nil
+}
@@ -0,0 +1,202 @@
+package db_handler
+
+import (
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ProjectPerformanceRow is the work a team completed for a project over one week: the points scored, the number
+// of tasks, the number of tasks of each level and their average level
+type ProjectPerformanceRow struct {
+	ProjectID                 int         `bson:"project_id"`
+	Project                   string      `bson:"project"`
+	Team                      string      `bson:"team"`
+	WeekStart                 time.Time   `bson:"week_start"`
+	WeekEnd                   time.Time   `bson:"week_end"`
+	Tasks                     int         `bson:"tasks"`
+	Levels                    map[int]int `bson:"levels"`
+	AverageLevel              float64     `bson:"average_level"`
+	TotalPerformancePoint     float64     `bson:"total_performance_point"`
+	TotalCreativeProcessPoint float64     `bson:"total_creative_process_point"`
+	TotalCreativeTaskPoint    float64     `bson:"total_creative_task_point"`
+	TotalBasePoint            float64     `bson:"total_base_point"`
+	Assignees                 []string    `bson:"assignees"`
+}
+
+// ProjectPerformanceTotal sums the tasks of one project over the whole period. A task of a member of several teams
+// is in the row of each team but counts once here.
+type ProjectPerformanceTotal struct {
+	ProjectID             int      `bson:"project_id"`
+	Project               string   `bson:"project"`
+	Tasks                 int      `bson:"tasks"`
+	AverageLevel          float64  `bson:"average_level"`
+	TotalPerformancePoint float64  `bson:"total_performance_point"`
+	Teams                 []string `bson:"teams"`
+}
+
+// ProjectPerformanceReport holds the rows sorted by project, team and week, and the totals of each project
+// sorted by points, highest first
+type ProjectPerformanceReport struct {
+	Rows     []ProjectPerformanceRow   `bson:"rows"`
+	Projects []ProjectPerformanceTotal `bson:"projects"`
+}
+
+type projectPerformanceKey struct {
+	projectID     int
+	project, team string
+}
+
+// GetProjectPerformance reports the points, tasks and levels of the tasks completed from startDate to endDate per
+// project, team and week. Projects, by name, alias or ID, and teams narrow the report when not empty.
+// Tasks count for the teams their assignee belonged to when they were done, as for team points. Tasks of a project
+// unknown to the catalog are reported under their Asana name and a zero ProjectID. Manual adjustments are not
+// tied to a project and are left out.
+func GetProjectPerformance(client *mongo.Client, dbName string, projects, teams []string, startDate, endDate time.Time) (*ProjectPerformanceReport, error) {
+	weeks := calendar.Weekly.Buckets(calendar.StartOfWeek(startDate), calendar.EndOfWeek(endDate))
+	weekIndex := make(map[int64]int, len(weeks))
+	for w, week := range weeks {
+		weekIndex[week[0].UnixMilli()] = w
+	}
+	rules, err := loadScoringRules(client, dbName, collections)
+	if err != nil {
+		return nil, err
+	}
+	attribution, err := collectionmodels.LoadTeamAttribution(client, dbName, collections.StaffMember)
+	if err != nil {
+		return nil, err
+	}
+	projectCatalog, err := collectionmodels.LoadProjectCatalog(client, dbName, collections.ProjectDetail)
+	if err != nil {
+		return nil, err
+	}
+	projectIDs := map[int]bool{}
+	for _, project := range projects {
+		if id, ok := projectCatalog.Resolve(project); ok {
+			projectIDs[id] = true
+		} else if id, err := strconv.Atoi(project); err == nil {
+			projectIDs[id] = true
+		}
+	}
+	keepProject := func(task *collectionmodels.CompletedTask) bool {
+		return len(projects) == 0 || projectIDs[task.ProjectID] || (task.ProjectID == 0 && slices.Contains(projects, task.Project))
+	}
+
+	tasks, err := collectionmodels.GetCompletedTasksByFilter(client, dbName, collections.CompletedTask, bson.M{
+		"done_date": bson.M{"$gte": weeks[0][0], "$lte": weeks[len(weeks)-1][1]},
+		"project":   bson.M{"$ne": ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cells := map[projectPerformanceKey][]ProjectPerformanceRow{}
+	// Project totals are keyed with an empty team
+	totals := map[projectPerformanceKey]*ProjectPerformanceRow{}
+	for i := range tasks {
+		task := &tasks[i]
+		if !keepProject(task) {
+			continue
+		}
+		w, ok := weekIndex[calendar.StartOfWeek(task.DoneDate).UnixMilli()]
+		if !ok {
+			continue
+		}
+		project := task.Project
+		if task.ProjectID != 0 {
+			project = projectCatalog.Name(task.ProjectID)
+		}
+		counted := false
+		for _, team := range attribution.TeamsOf(task) {
+			if len(teams) > 0 && !slices.Contains(teams, team) {
+				continue
+			}
+			key := projectPerformanceKey{task.ProjectID, project, team}
+			if cells[key] == nil {
+				cells[key] = make([]ProjectPerformanceRow, len(weeks))
+			}
+			addProjectTask(&cells[key][w], task, rules)
+			if !counted {
+				counted = true
+				projectKey := projectPerformanceKey{task.ProjectID, project, ""}
+				if totals[projectKey] == nil {
+					totals[projectKey] = &ProjectPerformanceRow{}
+				}
+				addProjectTask(totals[projectKey], task, rules)
+			}
+		}
+	}
+
+	keys := make([]projectPerformanceKey, 0, len(cells))
+	for key := range cells {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.project != b.project {
+			return a.project < b.project
+		}
+		if a.projectID != b.projectID {
+			return a.projectID < b.projectID
+		}
+		return a.team < b.team
+	})
+
+	report := &ProjectPerformanceReport{Rows: []ProjectPerformanceRow{}, Projects: []ProjectPerformanceTotal{}}
+	for _, key := range keys {
+		if n := len(report.Projects); n == 0 || report.Projects[n-1].ProjectID != key.projectID || report.Projects[n-1].Project != key.project {
+			sum := totals[projectPerformanceKey{key.projectID, key.project, ""}]
+			report.Projects = append(report.Projects, ProjectPerformanceTotal{
+				ProjectID:             key.projectID,
+				Project:               key.project,
+				Tasks:                 sum.Tasks,
+				AverageLevel:          sum.AverageLevel / float64(sum.Tasks),
+				TotalPerformancePoint: sum.TotalPerformancePoint,
+				Teams:                 []string{},
+			})
+		}
+		total := &report.Projects[len(report.Projects)-1]
+		for w, row := range cells[key] {
+			if row.Tasks == 0 {
+				continue
+			}
+			row.ProjectID, row.Project, row.Team = key.projectID, key.project, key.team
+			row.WeekStart, row.WeekEnd = weeks[w][0], weeks[w][1]
+			row.AverageLevel = row.AverageLevel / float64(row.Tasks)
+			report.Rows = append(report.Rows, row)
+
+			if !slices.Contains(total.Teams, key.team) {
+				total.Teams = append(total.Teams, key.team)
+			}
+		}
+	}
+	sort.SliceStable(report.Projects, func(i, j int) bool {
+		return report.Projects[i].TotalPerformancePoint > report.Projects[j].TotalPerformancePoint
+	})
+	return report, nil
+}
+
+// addProjectTask adds a task to a row, AverageLevel holds the sum of levels until the row is complete
+func addProjectTask(row *ProjectPerformanceRow, task *collectionmodels.CompletedTask, rules *scoringRules) {
+	var points PerformancePointTotal
+	addTaskPoints(&points, task, rules.levels, rules.tools)
+	row.TotalPerformancePoint += points.TotalPerformancePoint
+	row.TotalCreativeProcessPoint += points.TotalCreativeProcessPoint
+	row.TotalCreativeTaskPoint += points.TotalCreativeTaskPoint
+	row.TotalBasePoint += points.TotalBasePoint
+	row.Tasks++
+	if row.Levels == nil {
+		row.Levels = map[int]int{}
+	}
+	row.Levels[task.Level]++
+	row.AverageLevel += float64(task.Level)
+	if !slices.Contains(row.Assignees, task.AssigneeID) {
+		row.Assignees = append(row.Assignees, task.AssigneeID)
+	}
+}
@@ -0,0 +1,252 @@
+package db_handler
+
+import (
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AssetUnclassified is the asset type of tasks that cannot be matched to any ordered asset type
+const AssetUnclassified = "unclassified"
+
+// taskAssetType returns the asset type of the catalog a task delivers and the team it is ordered from: the task's
+// TaskType when set, else the only asset type of its team, else the asset type of its team named in the task name
+func taskAssetType(catalog []collectionmodels.AssetType, task *collectionmodels.CompletedTask) (string, string) {
+	var candidates []collectionmodels.AssetType
+	for _, at := range catalog {
+		if task.TaskType == at.Key {
+			return at.Key, at.Team
+		}
+		if at.Team == task.Team {
+			candidates = append(candidates, at)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0].Key, candidates[0].Team
+	}
+	name := strings.ToLower(task.TaskName)
+	for _, at := range candidates {
+		if at.Keyword != "" && strings.Contains(name, strings.ToLower(at.Keyword)) {
+			return at.Key, at.Team
+		}
+	}
+	return AssetUnclassified, task.Team
+}
+
+// ReconciliationRow is one week of one asset type of a project ordered from a team.
+// Due is what was ordered that week plus what was carried over unfinished from the week before.
+// Completed tasks first fulfil what is due, the rest is over-delivered. What stays unfinished is Outstanding
+// and is carried over to the next week.
+type ReconciliationRow struct {
+	ProjectID      int       `bson:"project_id"`
+	Project        string    `bson:"project"`
+	Team           string    `bson:"team"`
+	AssetType      string    `bson:"asset_type"`
+	WeekStart      time.Time `bson:"week_start"`
+	Ordered        int       `bson:"ordered"`
+	CarriedOver    int       `bson:"carried_over"`
+	Due            int       `bson:"due"`
+	Completed      int       `bson:"completed"`
+	Fulfilled      int       `bson:"fulfilled"`
+	OverDelivered  int       `bson:"over_delivered"`
+	Outstanding    int       `bson:"outstanding"`
+	FulfilmentRate float64   `bson:"fulfilment_rate"`
+	Assignees      []string  `bson:"assignees"`
+}
+
+// FulfilmentPoint sums the rows of one week
+type FulfilmentPoint struct {
+	WeekStart      time.Time `bson:"week_start"`
+	Ordered        int       `bson:"ordered"`
+	Due            int       `bson:"due"`
+	Completed      int       `bson:"completed"`
+	Fulfilled      int       `bson:"fulfilled"`
+	OverDelivered  int       `bson:"over_delivered"`
+	Outstanding    int       `bson:"outstanding"`
+	FulfilmentRate float64   `bson:"fulfilment_rate"`
+}
+
+// ReconciliationReport holds the rows sorted by project, team, asset type and week, and the weekly fulfilment
+// trend of all teams and of each team
+type ReconciliationReport struct {
+	Rows       []ReconciliationRow          `bson:"rows"`
+	Trend      []FulfilmentPoint            `bson:"trend"`
+	TeamTrends map[string][]FulfilmentPoint `bson:"team_trends"`
+}
+
+type reconciliationKey struct {
+	projectID                int
+	project, team, assetType string
+}
+
+type reconciliationCell struct {
+	ordered, completed int
+	assignees          []string
+}
+
+// reconciliationLookBack is how many weeks before the first reported week are reconciled to seed its carry-over,
+// what is still outstanding from earlier orders is considered settled
+const reconciliationLookBack = 12
+
+// GetOrderReconciliation compares the weekly orders of the weeks from startDate to endDate with the tasks completed
+// for them, per project, team and asset type. Projects and teams narrow the report when not empty.
+// The carry-over of the first week comes from the reconciliationLookBack weeks before it. Completed tasks of projects or asset types that were not ordered
+// are reported as over-delivered, those matching no asset type under AssetUnclassified. Orders and tasks are matched
+// on the catalog project, tasks of a project unknown to the catalog are reported under their Asana name and a zero ProjectID.
+func GetOrderReconciliation(client *mongo.Client, dbName string, projects, teams []string, startDate, endDate time.Time) (*ReconciliationReport, error) {
+	reported := calendar.Weekly.Buckets(calendar.StartOfWeek(startDate), calendar.EndOfWeek(endDate))
+	// The look-back weeks are reconciled only for the carry-over they leave to the first reported week
+	weeks := calendar.Weekly.Buckets(calendar.StartOfWeek(startDate).AddDate(0, 0, -7*reconciliationLookBack), calendar.EndOfWeek(endDate))
+	first := len(weeks) - len(reported)
+	from, to := weeks[0][0], weeks[len(weeks)-1][1]
+	weekIndex := make(map[int64]int, len(weeks))
+	for w, week := range weeks {
+		weekIndex[week[0].UnixMilli()] = w
+	}
+	projectCatalog, err := collectionmodels.LoadProjectCatalog(client, dbName, collections.ProjectDetail)
+	if err != nil {
+		return nil, err
+	}
+	var projectIDs []int
+	for _, name := range projects {
+		if id, ok := projectCatalog.Resolve(name); ok {
+			projectIDs = append(projectIDs, id)
+		}
+	}
+	keep := func(projectID int, project, team string) bool {
+		keepProject := len(projects) == 0 || slices.Contains(projectIDs, projectID) || (projectID == 0 && slices.Contains(projects, project))
+		return keepProject && (len(teams) == 0 || slices.Contains(teams, team))
+	}
+
+	cells := map[reconciliationKey][]reconciliationCell{}
+	cellOf := func(key reconciliationKey, week time.Time) *reconciliationCell {
+		if cells[key] == nil {
+			cells[key] = make([]reconciliationCell, len(weeks))
+		}
+		return &cells[key][weekIndex[calendar.StartOfWeek(week).UnixMilli()]]
+	}
+
+	catalog, err := collectionmodels.GetAllAssetTypes(client, dbName, collections.AssetType, true)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := collectionmodels.GetWeeklyOrdersByRange(client, dbName, collections.WeeklyOrder, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		for _, item := range order.Items {
+			if keep(order.ProjectID, order.Project, item.Team) {
+				key := reconciliationKey{order.ProjectID, projectCatalog.Name(order.ProjectID), item.Team, item.AssetType}
+				cellOf(key, order.StartWeek).ordered += item.Quantity
+			}
+		}
+	}
+
+	tasks, err := collectionmodels.GetCompletedTasksByFilter(client, dbName, collections.CompletedTask, bson.M{
+		"done_date": bson.M{"$gte": from, "$lte": to},
+		"project":   bson.M{"$ne": ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		asset, team := taskAssetType(catalog, &tasks[i])
+		project := tasks[i].Project
+		if tasks[i].ProjectID != 0 {
+			project = projectCatalog.Name(tasks[i].ProjectID)
+		}
+		if !keep(tasks[i].ProjectID, project, team) {
+			continue
+		}
+		cell := cellOf(reconciliationKey{tasks[i].ProjectID, project, team, asset}, tasks[i].DoneDate)
+		cell.completed++
+		if !slices.Contains(cell.assignees, tasks[i].AssigneeID) {
+			cell.assignees = append(cell.assignees, tasks[i].AssigneeID)
+		}
+	}
+
+	keys := make([]reconciliationKey, 0, len(cells))
+	for key := range cells {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.project != b.project {
+			return a.project < b.project
+		}
+		if a.projectID != b.projectID {
+			return a.projectID < b.projectID
+		}
+		if a.team != b.team {
+			return a.team < b.team
+		}
+		return a.assetType < b.assetType
+	})
+
+	report := &ReconciliationReport{Rows: []ReconciliationRow{}, Trend: newTrend(reported), TeamTrends: map[string][]FulfilmentPoint{}}
+	for _, key := range keys {
+		carry := 0
+		for w, cell := range cells[key] {
+			due := cell.ordered + carry
+			if due == 0 && cell.completed == 0 {
+				continue
+			}
+			fulfilled := min(cell.completed, due)
+			row := ReconciliationRow{
+				ProjectID:      key.projectID,
+				Project:        key.project,
+				Team:           key.team,
+				AssetType:      key.assetType,
+				WeekStart:      weeks[w][0],
+				Ordered:        cell.ordered,
+				CarriedOver:    carry,
+				Due:            due,
+				Completed:      cell.completed,
+				Fulfilled:      fulfilled,
+				OverDelivered:  cell.completed - fulfilled,
+				Outstanding:    due - fulfilled,
+				FulfilmentRate: percentage(float64(fulfilled), float64(due)),
+				Assignees:      cell.assignees,
+			}
+			carry = row.Outstanding
+			if w < first {
+				continue
+			}
+			if row.Assignees == nil {
+				row.Assignees = []string{}
+			}
+			if report.TeamTrends[key.team] == nil {
+				report.TeamTrends[key.team] = newTrend(reported)
+			}
+			report.Rows = append(report.Rows, row)
+			addToTrend(&report.Trend[w-first], row)
+			addToTrend(&report.TeamTrends[key.team][w-first], row)
+		}
+	}
+	return report, nil
+}
+
+func newTrend(weeks [][2]time.Time) []FulfilmentPoint {
+	trend := make([]FulfilmentPoint, len(weeks))
+	for w, week := range weeks {
+		trend[w].WeekStart = week[0]
+	}
+	return trend
+}
+
+func addToTrend(point *FulfilmentPoint, row ReconciliationRow) {
+	point.Ordered += row.Ordered
+	point.Due += row.Due
+	point.Completed += row.Completed
+	point.Fulfilled += row.Fulfilled
+	point.OverDelivered += row.OverDelivered
+	point.Outstanding += row.Outstanding
+	point.FulfilmentRate = percentage(float64(point.Fulfilled), float64(point.Due))
+}
@@ -0,0 +1,195 @@
+package db_handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// scoringRules is the scoring config points are computed with
+type scoringRules struct {
+	levels  []collectionmodels.Level
+	tools   []collectionmodels.CreativeTool
+	version string
+}
+
+func loadScoringRules(client *mongo.Client, dbName string, collections collectionmodels.Collections) (*scoringRules, error) {
+	levels, err := collectionmodels.GetAllLevels(client, dbName, collections.Level, false)
+	if err != nil {
+		return nil, err
+	}
+	tools, err := collectionmodels.GetAllCreativeTools(client, dbName, collections.CreativeTools, false)
+	if err != nil {
+		return nil, err
+	}
+	return &scoringRules{levels: levels, tools: tools, version: RulesetVersion(levels, tools)}, nil
+}
+
+// RulesetVersion identifies a scoring config, any change of levels or creative tools gives another version
+func RulesetVersion(levels []collectionmodels.Level, tools []collectionmodels.CreativeTool) string {
+	data, _ := json.Marshal(struct {
+		Levels []collectionmodels.Level
+		Tools  []collectionmodels.CreativeTool
+	}{levels, tools})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// isWholeWeek reports whether a bucket spans a whole week
+func isWholeWeek(bucket [2]time.Time) bool {
+	return bucket[0].Equal(calendar.StartOfWeek(bucket[0])) && bucket[1].Equal(calendar.EndOfWeek(bucket[0]))
+}
+
+// isSnapshotWeek reports whether a bucket is a whole week that has ended. Partial weeks and the current week
+// are not snapshotted when read, their points would be frozen before all their tasks are done.
+// Tasks only arrive with the weekly sync, which rebuilds the snapshots of the week it loads even before it ends.
+func isSnapshotWeek(bucket [2]time.Time) bool {
+	return isWholeWeek(bucket) && bucket[1].Before(time.Now())
+}
+
+// readSnapshots fills totals from the up to date snapshots of whole weeks, and returns the buckets it filled
+func readSnapshots(client *mongo.Client, dbName string, collections collectionmodels.Collections, identifiers []string, kind collectionmodels.IdentifierKind, buckets [][2]time.Time, version string, totals map[string][]PerformancePointTotal) (map[string][]bool, error) {
+	snapshots, err := collectionmodels.GetPerformanceSnapshots(client, dbName, collections.PerformanceSnapshot, identifiers, kind, version, buckets[0][0], buckets[len(buckets)-1][1])
+	if err != nil {
+		return nil, err
+	}
+	// Dates come back from the database in UTC, match weeks on the instant
+	index := make(map[int64]int, len(buckets))
+	for b, bucket := range buckets {
+		if isSnapshotWeek(bucket) {
+			index[bucket[0].UnixMilli()] = b
+		}
+	}
+	filled := map[string][]bool{}
+	for _, s := range snapshots {
+		b, ok := index[s.WeekStart.UnixMilli()]
+		if !ok {
+			continue
+		}
+		if filled[s.Identifier] == nil {
+			filled[s.Identifier] = make([]bool, len(buckets))
+		}
+		filled[s.Identifier][b] = true
+		totals[s.Identifier][b] = PerformancePointTotal{
+			TotalPerformancePoint:     s.TotalPerformancePoint,
+			TotalCreativeProcessPoint: s.TotalCreativeProcessPoint,
+			TotalCreativeTaskPoint:    s.TotalCreativeTaskPoint,
+			TotalBasePoint:            s.TotalBasePoint,
+		}
+	}
+	return filled, nil
+}
+
+// writeSnapshots saves the computed points of whole past weeks that were not read from a snapshot, and returns
+// how many it saved. A rebuild also saves the current week, the sync that rebuilds it has loaded all its tasks.
+func writeSnapshots(client *mongo.Client, dbName string, collections collectionmodels.Collections, identifiers []string, kind collectionmodels.IdentifierKind, buckets [][2]time.Time, version string, totals map[string][]PerformancePointTotal, skip map[string][]bool, rebuild bool) (int, error) {
+	now := time.Now()
+	var snapshots []collectionmodels.PerformanceSnapshot
+	for _, id := range identifiers {
+		for b, bucket := range buckets {
+			started := bucket[0].Before(now)
+			if (skip[id] != nil && skip[id][b]) || !isWholeWeek(bucket) || !(isSnapshotWeek(bucket) || (rebuild && started)) {
+				continue
+			}
+			total := totals[id][b]
+			snapshots = append(snapshots, collectionmodels.PerformanceSnapshot{
+				Identifier:                id,
+				Kind:                      kind,
+				WeekStart:                 bucket[0],
+				WeekEnd:                   bucket[1],
+				TotalPerformancePoint:     total.TotalPerformancePoint,
+				TotalCreativeProcessPoint: total.TotalCreativeProcessPoint,
+				TotalCreativeTaskPoint:    total.TotalCreativeTaskPoint,
+				TotalBasePoint:            total.TotalBasePoint,
+				RulesetVersion:            version,
+				BuiltAt:                   now,
+			})
+		}
+	}
+	if err := collectionmodels.SavePerformanceSnapshots(client, dbName, collections.PerformanceSnapshot, snapshots); err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
+
+// SnapshotRebuild is the outcome of RebuildPerformanceSnapshots
+type SnapshotRebuild struct {
+	Weeks     int
+	Members   int
+	Teams     int
+	Projects  int
+	Snapshots int
+}
+
+// RebuildPerformanceSnapshots recomputes the snapshots of every member, team and project for the whole weeks between
+// startDate and endDate, after tasks or assignments of those weeks changed. Snapshots of closed weeks are kept.
+func RebuildPerformanceSnapshots(client *mongo.Client, dbName string, startDate, endDate time.Time) (*SnapshotRebuild, error) {
+	rules, err := loadScoringRules(client, dbName, collections)
+	if err != nil {
+		return nil, err
+	}
+	attribution, err := collectionmodels.LoadTeamAttribution(client, dbName, collections.StaffMember)
+	if err != nil {
+		return nil, err
+	}
+	projectCatalog, err := collectionmodels.LoadProjectCatalog(client, dbName, collections.ProjectDetail)
+	if err != nil {
+		return nil, err
+	}
+	weekFrom := calendar.StartOfWeek(startDate)
+	buckets := calendar.Weekly.Buckets(weekFrom, calendar.EndOfWeek(endDate))
+	if _, err := collectionmodels.DeletePerformanceSnapshots(client, dbName, collections.PerformanceSnapshot, weekFrom, &buckets[len(buckets)-1][0], ""); err != nil {
+		return nil, err
+	}
+
+	res := &SnapshotRebuild{Weeks: len(buckets)}
+	for _, kind := range []collectionmodels.IdentifierKind{collectionmodels.KindMember, collectionmodels.KindTeam, collectionmodels.KindProject} {
+		var identifiers []string
+		switch kind {
+		case collectionmodels.KindMember:
+			identifiers = attribution.Emails()
+			res.Members = len(identifiers)
+		case collectionmodels.KindTeam:
+			identifiers = attribution.Teams()
+			res.Teams = len(identifiers)
+		case collectionmodels.KindProject:
+			for _, id := range projectCatalog.IDs() {
+				identifiers = append(identifiers, strconv.Itoa(id))
+			}
+			res.Projects = len(identifiers)
+		}
+		if len(identifiers) == 0 {
+			continue
+		}
+		totals := make(map[string][]PerformancePointTotal, len(identifiers))
+		for _, id := range identifiers {
+			totals[id] = make([]PerformancePointTotal, len(buckets))
+		}
+		// Only the snapshots of closed weeks are left, they keep their points
+		locked, err := readSnapshots(client, dbName, collections, identifiers, kind, buckets, rules.version, totals)
+		if err != nil {
+			return nil, err
+		}
+		if err := computePoints(client, dbName, collections, identifiers, kind, buckets, rules, totals, locked); err != nil {
+			return nil, err
+		}
+		written, err := writeSnapshots(client, dbName, collections, identifiers, kind, buckets, rules.version, totals, locked, true)
+		if err != nil {
+			return nil, err
+		}
+		res.Snapshots += written
+	}
+	return res, nil
+}
+
+// InvalidateTeamSnapshots drops the unlocked team snapshots from the week of from onward, after member assignments changed
+func InvalidateTeamSnapshots(client *mongo.Client, dbName string, from time.Time) error {
+	_, err := collectionmodels.DeletePerformanceSnapshots(client, dbName, collections.PerformanceSnapshot, calendar.StartOfWeek(from), nil, collectionmodels.KindTeam)
+	return err
+}
@@ -0,0 +1,118 @@
+package db_handler
+
+import (
+	"log"
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CloseTeamWeek closes the week containing at for a team: the snapshots of the team and of its members that week
+// are brought up to date then locked, later task or scoring changes no longer move them.
+// Only weeks that have ended can be closed. The closure is inserted first so that a concurrent call fails
+// before touching the snapshots, and it is removed again when the snapshots cannot be locked.
+func CloseTeamWeek(client *mongo.Client, dbName, team string, at time.Time, closedBy string) (*collectionmodels.WeekClosure, error) {
+	weekStart, weekEnd := calendar.StartOfWeek(at), calendar.EndOfWeek(at)
+	if !weekEnd.Before(time.Now()) {
+		return nil, collectionmodels.ErrWeekNotEnded
+	}
+	attribution, err := collectionmodels.LoadTeamAttribution(client, dbName, collections.StaffMember)
+	if err != nil {
+		return nil, err
+	}
+	members := attribution.MembersOf(team, weekStart, weekEnd)
+	slices.Sort(members)
+
+	closure := &collectionmodels.WeekClosure{
+		Team:      team,
+		WeekStart: weekStart,
+		WeekEnd:   weekEnd,
+		Members:   members,
+		ClosedBy:  closedBy,
+		ClosedAt:  time.Now(),
+	}
+	if err := collectionmodels.InsertWeekClosure(client, dbName, collections.WeekClosure, closure); err != nil {
+		return nil, err
+	}
+	if err := lockClosureSnapshots(client, dbName, closure); err != nil {
+		// Undo the closure, the snapshots it managed to lock are released as on reopening
+		if undoErr := collectionmodels.DeleteWeekClosure(client, dbName, collections.WeekClosure, closure.ID); undoErr != nil {
+			log.Printf("Closing the week of %s for %s failed and the closure could not be removed: %v", weekStart.Format("2006-01-02"), team, undoErr)
+		} else if undoErr := releaseClosureSnapshots(client, dbName, closure); undoErr != nil {
+			log.Printf("Closing the week of %s for %s failed and its snapshots could not be released: %v", weekStart.Format("2006-01-02"), team, undoErr)
+		}
+		return nil, err
+	}
+	return closure, nil
+}
+
+// lockClosureSnapshots brings the snapshots of the team and members of a closure up to date and locks them
+func lockClosureSnapshots(client *mongo.Client, dbName string, closure *collectionmodels.WeekClosure) error {
+	// Reading the week writes any missing snapshot
+	snapshotColl := collections.PerformanceSnapshot
+	if _, err := GetPerformancePointsBatch(client, dbName, collections, []string{closure.Team}, closure.WeekStart, closure.WeekEnd, collectionmodels.KindTeam, calendar.Weekly); err != nil {
+		return err
+	}
+	if err := collectionmodels.LockPerformanceSnapshots(client, dbName, snapshotColl, []string{closure.Team}, collectionmodels.KindTeam, closure.WeekStart); err != nil {
+		return err
+	}
+	if len(closure.Members) > 0 {
+		if _, err := GetPerformancePointsBatch(client, dbName, collections, closure.Members, closure.WeekStart, closure.WeekEnd, collectionmodels.KindMember, calendar.Weekly); err != nil {
+			return err
+		}
+		if err := collectionmodels.LockPerformanceSnapshots(client, dbName, snapshotColl, closure.Members, collectionmodels.KindMember, closure.WeekStart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReopenTeamWeek reopens a closed week. The team's snapshot and those of members not locked by another team's
+// closure of the same week are dropped, to be computed again from the current tasks.
+func ReopenTeamWeek(client *mongo.Client, dbName string, id primitive.ObjectID, reopenedBy string) error {
+	closureColl := collections.WeekClosure
+	closure, err := collectionmodels.GetWeekClosure(client, dbName, closureColl, id)
+	if err != nil {
+		return err
+	}
+	if err := collectionmodels.ReopenWeekClosure(client, dbName, closureColl, id, reopenedBy); err != nil {
+		return err
+	}
+	return releaseClosureSnapshots(client, dbName, closure)
+}
+
+// releaseClosureSnapshots drops the snapshots a closure locked, once it is no longer active. Those of members
+// still locked by another team's closure of the same week are kept.
+func releaseClosureSnapshots(client *mongo.Client, dbName string, closure *collectionmodels.WeekClosure) error {
+	closureColl := collections.WeekClosure
+	snapshotColl := collections.PerformanceSnapshot
+	if err := collectionmodels.ReleasePerformanceSnapshots(client, dbName, snapshotColl, []string{closure.Team}, collectionmodels.KindTeam, closure.WeekStart); err != nil {
+		return err
+	}
+
+	others, err := collectionmodels.GetWeekClosuresOfWeek(client, dbName, closureColl, closure.WeekStart)
+	if err != nil {
+		return err
+	}
+	var release []string
+	for _, email := range closure.Members {
+		stillClosed := false
+		for _, other := range others {
+			if slices.Contains(other.Members, email) {
+				stillClosed = true
+				break
+			}
+		}
+		if !stillClosed {
+			release = append(release, email)
+		}
+	}
+	if len(release) == 0 {
+		return nil
+	}
+	return collectionmodels.ReleasePerformanceSnapshots(client, dbName, snapshotColl, release, collectionmodels.KindMember, closure.WeekStart)
+}
@@ -0,0 +1,30 @@
+package db_handler
+
+import (
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetEffectiveWeeklyTargets resolves the target of every team for every week of [startDate, endDate].
+// Rules and overrides of all teams are loaded with one query each.
+func GetEffectiveWeeklyTargets(client *mongo.Client, dbName string, teams []string, startDate, endDate time.Time) ([]collectionmodels.EffectiveWeeklyTarget, error) {
+	rules, err := collectionmodels.GetWeeklyTargetsForTeams(client, dbName, collections.WeeklyTarget, teams, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := collectionmodels.GetWeeklyTargetOverrides(client, dbName, collections.WeeklyTargetOverride, teams, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []collectionmodels.EffectiveWeeklyTarget
+	for _, team := range teams {
+		for _, week := range calendar.SplitWeeks(startDate, endDate) {
+			results = append(results, collectionmodels.ResolveWeeklyTarget(rules, overrides, team, week[0], week[1]))
+		}
+	}
+	return results, nil
+}
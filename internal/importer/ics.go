@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCalendar = errors.New("invalid ICS calendar")
+
+// Event is a VEVENT of an ICS calendar, reduced to the whole days it covers
+type Event struct {
+	// Line is the line of the BEGIN:VEVENT in the file
+	Line     int
+	UID      string
+	Summary  string
+	DateFrom time.Time
+	// DateTo is the last day of the event, included
+	DateTo time.Time
+}
+
+// recurrenceProperties make an event stand for several occurrences, which are not expanded
+var recurrenceProperties = []string{"RRULE", "RDATE", "EXDATE", "RECURRENCE-ID"}
+
+// ReadEvents parses the VEVENTs of an ICS calendar. Times are reduced to days in loc,
+// and the exclusive DTEND of all day events is turned into an inclusive last day.
+// Recurring events and the overrides of their occurrences are not imported, each one is reported as a RowError.
+func ReadEvents(r io.Reader, loc *time.Location) ([]Event, []RowError, error) {
+	lines, starts, err := unfoldLines(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var events []Event
+	rejected := []RowError{}
+	var current map[string]string
+	begin := 0
+	for i, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			current = map[string]string{}
+			begin = starts[i]
+		case line == "END:VEVENT":
+			if current == nil {
+				return nil, nil, ErrInvalidCalendar
+			}
+			if property := recurrenceOf(current); property != "" {
+				rejected = append(rejected, RowError{Line: begin, Field: property, Message: "recurring events are not supported, add each occurrence as its own event"})
+				current = nil
+				continue
+			}
+			e, err := newEvent(current, loc)
+			if err != nil {
+				return nil, nil, err
+			}
+			e.Line = begin
+			events = append(events, e)
+			current = nil
+		case current != nil:
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			// Drop parameters such as DTSTART;VALUE=DATE or DTSTART;TZID=...
+			name, params, _ := strings.Cut(name, ";")
+			if name == "DTSTART" || name == "DTEND" {
+				if _, tz, ok := strings.Cut(params, "TZID="); ok {
+					current[name+"_TZID"] = strings.Split(tz, ";")[0]
+				}
+			}
+			current[name] = value
+		}
+	}
+	if current != nil {
+		return nil, nil, ErrInvalidCalendar
+	}
+	return events, rejected, nil
+}
+
+// recurrenceOf returns the first recurrence property of an event, empty for a single event
+func recurrenceOf(props map[string]string) string {
+	for _, property := range recurrenceProperties {
+		if _, ok := props[property]; ok {
+			return property
+		}
+	}
+	return ""
+}
+
+// unfoldLines joins the continuation lines of RFC 5545, which start with a space or a tab.
+// starts holds the 1-based line in the file where each unfolded line begins.
+func unfoldLines(r io.Reader) (lines []string, starts []int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+		starts = append(starts, n)
+	}
+	return lines, starts, scanner.Err()
+}
+
+func newEvent(props map[string]string, loc *time.Location) (Event, error) {
+	start, allDay, err := parseICSTime(props["DTSTART"], props["DTSTART_TZID"], loc)
+	if err != nil {
+		return Event{}, err
+	}
+	e := Event{UID: props["UID"], Summary: unescapeText(props["SUMMARY"]), DateFrom: day(start, loc), DateTo: day(start, loc)}
+	if e.UID == "" {
+		// Without a UID re-importing the calendar could not update the event
+		e.UID = e.Summary + "@" + e.DateFrom.Format("20060102")
+	}
+	if endStr, ok := props["DTEND"]; ok {
+		end, _, err := parseICSTime(endStr, props["DTEND_TZID"], loc)
+		if err != nil {
+			return Event{}, err
+		}
+		if allDay {
+			end = end.AddDate(0, 0, -1)
+		} else {
+			// An event ending at midnight does not cover the next day
+			end = end.Add(-time.Nanosecond)
+		}
+		if last := day(end, loc); last.After(e.DateFrom) {
+			e.DateTo = last
+		}
+	}
+	return e, nil
+}
+
+// parseICSTime reads a DATE (20260101) or DATE-TIME (20260101T090000, with Z for UTC) value
+func parseICSTime(value, tzid string, loc *time.Location) (time.Time, bool, error) {
+	if len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return t, false, ErrInvalidCalendar
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return t, false, ErrInvalidCalendar
+		}
+		return t, false, nil
+	}
+	eventLoc := loc
+	if tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			eventLoc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, eventLoc)
+	if err != nil {
+		return t, false, ErrInvalidCalendar
+	}
+	return t, false, nil
+}
+
+func day(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
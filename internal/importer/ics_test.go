@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+const dayLayout = "2006-01-02"
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+// vcalendar wraps ICS lines in a calendar, with CRLF line endings as RFC 5545 requires
+func vcalendar(lines ...string) string {
+	all := append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, lines...)
+	all = append(all, "END:VCALENDAR")
+	return strings.Join(all, "\r\n") + "\r\n"
+}
+
+func TestParseICSTime(t *testing.T) {
+	saigon := mustLocation(t, "Asia/Ho_Chi_Minh")
+	tests := []struct {
+		name    string
+		value   string
+		tzid    string
+		want    time.Time
+		allDay  bool
+		invalid bool
+	}{
+		{"date", "20260101", "", time.Date(2026, 1, 1, 0, 0, 0, 0, saigon), true, false},
+		{"utc date time", "20260101T170000Z", "", time.Date(2026, 1, 1, 17, 0, 0, 0, time.UTC), false, false},
+		{"floating date time", "20260101T090000", "", time.Date(2026, 1, 1, 9, 0, 0, 0, saigon), false, false},
+		{"date time with tzid", "20260101T090000", "Europe/Paris", time.Date(2026, 1, 1, 9, 0, 0, 0, mustLocation(t, "Europe/Paris")), false, false},
+		{"unknown tzid falls back", "20260101T090000", "Nowhere/Land", time.Date(2026, 1, 1, 9, 0, 0, 0, saigon), false, false},
+		{"invalid date", "20261301", "", time.Time{}, false, true},
+		{"invalid utc date time", "2026-01-01T17:00:00Z", "", time.Time{}, false, true},
+		{"empty", "", "", time.Time{}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, allDay, err := parseICSTime(tt.value, tt.tzid, saigon)
+			if tt.invalid {
+				if !errors.Is(err, ErrInvalidCalendar) {
+					t.Fatalf("parseICSTime(%q) error = %v, want %v", tt.value, err, ErrInvalidCalendar)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) || allDay != tt.allDay {
+				t.Errorf("parseICSTime(%q, %q) = %v, %v, want %v, %v", tt.value, tt.tzid, got, allDay, tt.want, tt.allDay)
+			}
+		})
+	}
+}
+
+func TestReadEvents(t *testing.T) {
+	saigon := mustLocation(t, "Asia/Ho_Chi_Minh")
+	type event struct {
+		line     int
+		uid      string
+		summary  string
+		from, to string
+	}
+	tests := []struct {
+		name     string
+		ics      string
+		events   []event
+		rejected []RowError
+	}{
+		{
+			name: "all day event ends the day before DTEND",
+			ics: vcalendar(
+				"BEGIN:VEVENT", "UID:tet@example.com", "SUMMARY:Tet holiday",
+				"DTSTART;VALUE=DATE:20260216", "DTEND;VALUE=DATE:20260221", "END:VEVENT",
+			),
+			events: []event{{3, "tet@example.com", "Tet holiday", "2026-02-16", "2026-02-20"}},
+		},
+		{
+			name: "single all day event",
+			ics: vcalendar(
+				"BEGIN:VEVENT", "UID:a", "SUMMARY:Day off", "DTSTART;VALUE=DATE:20260430", "DTEND;VALUE=DATE:20260501", "END:VEVENT",
+			),
+			events: []event{{3, "a", "Day off", "2026-04-30", "2026-04-30"}},
+		},
+		{
+			name: "timed event ending at midnight keeps to its day",
+			ics: vcalendar(
+				"BEGIN:VEVENT", "UID:b", "SUMMARY:Offsite", "DTSTART:20260310T090000", "DTEND:20260311T000000", "END:VEVENT",
+			),
+			events: []event{{3, "b", "Offsite", "2026-03-10", "2026-03-10"}},
+		},
+		{
+			name: "utc times are reduced to days of the location",
+			ics: vcalendar(
+				"BEGIN:VEVENT", "UID:c", "SUMMARY:Late", "DTSTART:20260310T200000Z", "DTEND:20260310T220000Z", "END:VEVENT",
+			),
+			events: []event{{3, "c", "Late", "2026-03-11", "2026-03-11"}},
+		},
+		{
+			name:   "event without DTEND lasts one day",
+			ics:    vcalendar("BEGIN:VEVENT", "UID:d", "SUMMARY:Short", "DTSTART;VALUE=DATE:20260601", "END:VEVENT"),
+			events: []event{{3, "d", "Short", "2026-06-01", "2026-06-01"}},
+		},
+		{
+			name: "folded lines, escaped text and missing UID",
+			ics: vcalendar(
+				"BEGIN:VEVENT", "SUMMARY:Company trip\\, day", " one", "DTSTART;VALUE=DATE:20260701", "END:VEVENT",
+			),
+			events: []event{{3, "Company trip, dayone@20260701", "Company trip, dayone", "2026-07-01", "2026-07-01"}},
+		},
+		{
+			name: "recurring events and overrides are rejected",
+			ics: vcalendar(
+				"BEGIN:VEVENT", "UID:e", "SUMMARY:Weekly", "DTSTART;VALUE=DATE:20260105", "RRULE:FREQ=WEEKLY", "END:VEVENT",
+				"BEGIN:VEVENT", "UID:e", "SUMMARY:Moved", "DTSTART;VALUE=DATE:20260113", "RECURRENCE-ID;VALUE=DATE:20260112", "END:VEVENT",
+				"BEGIN:VEVENT", "UID:f", "SUMMARY:Kept", "DTSTART;VALUE=DATE:20260120", "END:VEVENT",
+				"BEGIN:VEVENT", "UID:g", "SUMMARY:Skipping", "DTSTART;VALUE=DATE:20260105", "EXDATE;VALUE=DATE:20260112", "END:VEVENT",
+			),
+			events: []event{{15, "f", "Kept", "2026-01-20", "2026-01-20"}},
+			rejected: []RowError{
+				{Line: 3, Field: "RRULE"},
+				{Line: 9, Field: "RECURRENCE-ID"},
+				{Line: 20, Field: "EXDATE"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, rejected, err := ReadEvents(strings.NewReader(tt.ics), saigon)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != len(tt.events) {
+				t.Fatalf("got %d events, want %d: %+v", len(events), len(tt.events), events)
+			}
+			for i, want := range tt.events {
+				got := events[i]
+				if got.Line != want.line || got.UID != want.uid || got.Summary != want.summary ||
+					got.DateFrom.Format(dayLayout) != want.from || got.DateTo.Format(dayLayout) != want.to {
+					t.Errorf("event %d = {%d %q %q %s %s}, want %+v", i, got.Line, got.UID, got.Summary,
+						got.DateFrom.Format(dayLayout), got.DateTo.Format(dayLayout), want)
+				}
+			}
+			if len(rejected) != len(tt.rejected) {
+				t.Fatalf("got %d rejected events, want %d: %+v", len(rejected), len(tt.rejected), rejected)
+			}
+			for i, want := range tt.rejected {
+				if rejected[i].Line != want.Line || rejected[i].Field != want.Field {
+					t.Errorf("rejected %d = %+v, want line %d field %s", i, rejected[i], want.Line, want.Field)
+				}
+			}
+		})
+	}
+}
+
+func TestReadEventsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		ics  string
+	}{
+		{"unterminated event", vcalendar("BEGIN:VEVENT", "UID:a", "DTSTART;VALUE=DATE:20260101")},
+		{"end without begin", vcalendar("END:VEVENT")},
+		{"invalid start", vcalendar("BEGIN:VEVENT", "UID:a", "DTSTART:tomorrow", "END:VEVENT")},
+		{"missing start", vcalendar("BEGIN:VEVENT", "UID:a", "END:VEVENT")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ReadEvents(strings.NewReader(tt.ics), time.UTC); !errors.Is(err, ErrInvalidCalendar) {
+				t.Errorf("ReadEvents error = %v, want %v", err, ErrInvalidCalendar)
+			}
+		})
+	}
+}
@@ -0,0 +1,473 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Kind string
+
+const (
+	KindMembers        Kind = "members"
+	KindWeeklyOrders   Kind = "weekly-orders"
+	KindWeeklyTargets  Kind = "weekly-targets"
+	KindProjectDetails Kind = "project-details"
+)
+
+var ErrUnknownKind = errors.New("unknown import type")
+
+// Collection returns the name of the collection records of kind are written to
+func (kind Kind) Collection(collections collectionmodels.Collections) (string, error) {
+	switch kind {
+	case KindMembers:
+		return collections.StaffMember, nil
+	case KindWeeklyOrders:
+		return collections.WeeklyOrder, nil
+	case KindWeeklyTargets:
+		return collections.WeeklyTarget, nil
+	case KindProjectDetails:
+		return collections.ProjectDetail, nil
+	}
+	return "", ErrUnknownKind
+}
+
+type RowError struct {
+	Line    int
+	Field   string
+	Message string
+}
+
+// Report describes what an import did, or would do for a dry run.
+// Nothing is written when Errors is not empty.
+type Report struct {
+	Kind     Kind
+	DryRun   bool
+	Rows     int
+	Inserted int
+	Updated  int
+	Errors   []RowError
+}
+
+type write struct {
+	filter bson.M
+	update bson.M
+}
+
+// operation is what one valid row turns into: an upsert of the record identified
+// by recordKey, followed by optional extra writes on the same record
+type operation struct {
+	line      int
+	rowKey    string
+	recordKey string
+	upsert    write
+	then      []write
+	// span is set for team targets, which must not share a week with another target of the team
+	span *dateSpan
+	// unchanged matches the stored record when the row changes nothing, only changes must keep off closed weeks
+	unchanged bson.M
+}
+
+type dateSpan struct {
+	group string
+	from  time.Time
+	to    *time.Time
+}
+
+// overlaps reports whether the spans share a week, as targets set the target of whole weeks
+func (a *dateSpan) overlaps(b *dateSpan) bool {
+	return a.group == b.group &&
+		(a.to == nil || !calendar.StartOfWeek(b.from).After(calendar.EndOfWeek(*a.to))) &&
+		(b.to == nil || !calendar.StartOfWeek(a.from).After(calendar.EndOfWeek(*b.to)))
+}
+
+// restore revives soft deleted records that are imported again
+var restore = bson.M{"deleted_at": "", "deleted_by": ""}
+
+var upsertOptions = options.Update().SetUpsert(true)
+
+// references are the catalogs rows are checked against
+type references struct {
+	assetTypes []collectionmodels.AssetType
+	projects   *collectionmodels.ProjectCatalog
+}
+
+func buildOperation(kind Kind, r *rowReader, refs *references) (operation, error) {
+	switch kind {
+	case KindMembers:
+		return memberOperation(r), nil
+	case KindWeeklyOrders:
+		return weeklyOrderOperation(r, refs), nil
+	case KindWeeklyTargets:
+		return weeklyTargetOperation(r), nil
+	case KindProjectDetails:
+		return projectDetailOperation(r, refs.projects), nil
+	}
+	return operation{}, ErrUnknownKind
+}
+
+// Columns: MemberID, Name, Email, YOB, Team, Role, JoinedAt, WorkPercent.
+// Several rows with the same email add one membership each.
+func memberOperation(r *rowReader) operation {
+	memberID := r.required("MemberID")
+	name := r.required("Name")
+	email := r.required("Email")
+	yob := r.integer("YOB", false)
+	team := r.optional("Team")
+	role := r.optional("Role")
+	joinedAt := r.date("JoinedAt", false)
+	workPercent := r.integer("WorkPercent", false)
+	if role != "" && team == "" {
+		r.fail("Team", "is required when Role is set")
+	}
+
+	set := bson.M{"id": memberID, "name": name, "yob": yob}
+	if workPercent > 100 {
+		r.fail("WorkPercent", "must not be above 100")
+	} else if workPercent > 0 {
+		set["work_percent"] = workPercent
+	}
+	var from time.Time
+	if joinedAt != nil {
+		set["joined_at"] = *joinedAt
+		from = *joinedAt
+	}
+
+	assignments := []collectionmodels.TeamAssignment{}
+	var then []write
+	if team != "" {
+		a := collectionmodels.TeamAssignment{Team: team, Role: role, From: from}
+		assignments = append(assignments, a)
+		// Existing members get the membership unless they already hold it
+		then = append(then, write{
+			filter: bson.M{"email": email, "assignments": bson.M{"$not": bson.M{"$elemMatch": bson.M{"team": team, "to": nil}}}},
+			update: bson.M{"$push": bson.M{"assignments": a}},
+		})
+	}
+
+	return operation{
+		rowKey:    email + "|" + team,
+		recordKey: email,
+		upsert: write{
+			filter: bson.M{"email": email},
+			update: bson.M{
+				"$set":         set,
+				"$setOnInsert": bson.M{"assignments": assignments},
+				"$unset":       restore,
+			},
+		},
+		then: then,
+	}
+}
+
+// Columns: StartWeek, Project, Goal, Strategy, AssetType, Team, Quantity, Priority, Notes.
+// Each row sets one line item of the order, several rows of the same project and week fill one order.
+// Project is a name or an alias of a project of the catalog. Goal and Strategy are only changed when given.
+func weeklyOrderOperation(r *rowReader, refs *references) operation {
+	startWeek := r.date("StartWeek", true)
+	project := r.required("Project")
+	projectID, known := refs.projects.Resolve(project)
+	if project != "" && !known {
+		r.fail("Project", collectionmodels.ErrUnknownProject.Error())
+	}
+	item := collectionmodels.OrderItem{
+		AssetType: r.required("AssetType"),
+		Team:      r.optional("Team"),
+		Priority:  r.optional("Priority"),
+		Notes:     r.optional("Notes"),
+	}
+	errorCount := len(r.errors)
+	item.Quantity = r.integer("Quantity", true)
+	if len(r.errors) == errorCount && item.Quantity == 0 {
+		r.fail("Quantity", "must be positive")
+	}
+	if item.AssetType != "" && item.Quantity > 0 {
+		items := []collectionmodels.OrderItem{item}
+		if err := collectionmodels.ResolveOrderItems(refs.assetTypes, items); err != nil {
+			r.fail("AssetType", err.Error())
+		}
+		item = items[0]
+	}
+	set := bson.M{"project": refs.projects.Name(projectID)}
+	for field, key := range map[string]string{"Goal": "goal", "Strategy": "strategy"} {
+		if v := r.optional(field); v != "" {
+			set[key] = v
+		}
+	}
+	if startWeek == nil || !known {
+		return operation{}
+	}
+	key := fmt.Sprintf("%d|%s", projectID, startWeek.Format(time.RFC3339))
+	filter := bson.M{"project_id": projectID, "start_week": *startWeek}
+	update := bson.M{"$set": set, "$setOnInsert": bson.M{"items": []collectionmodels.OrderItem{}}, "$unset": restore}
+	return operation{
+		rowKey:    fmt.Sprintf("%s|%s|%s", key, item.AssetType, item.Team),
+		recordKey: key,
+		upsert:    write{filter: filter, update: update},
+		then: []write{
+			{filter: filter, update: bson.M{"$pull": bson.M{"items": bson.M{"asset_type": item.AssetType, "team": item.Team}}}},
+			{filter: filter, update: bson.M{"$push": bson.M{"items": item}}},
+		},
+	}
+}
+
+// Columns: Team, Point, DateFrom, DateTo, EveryWeeks. An empty DateTo keeps the target for good.
+// A row updates the target of the team starting on the same DateFrom.
+func weeklyTargetOperation(r *rowReader) operation {
+	team := r.required("Team")
+	point := r.integer("Point", true)
+	dateFrom := r.date("DateFrom", true)
+	dateTo := r.date("DateTo", false)
+	everyWeeks := r.integer("EveryWeeks", false)
+	if dateFrom == nil {
+		return operation{}
+	}
+	if dateTo != nil && dateTo.Before(*dateFrom) {
+		r.fail("DateTo", "must not be before DateFrom")
+	}
+	key := fmt.Sprintf("%s|%s", team, dateFrom.Format(time.RFC3339))
+	// Targets added from the API leave out every_weeks when it is 0
+	var storedEveryWeeks interface{} = everyWeeks
+	if everyWeeks == 0 {
+		storedEveryWeeks = bson.M{"$in": bson.A{0, nil}}
+	}
+	return operation{
+		rowKey:    key,
+		recordKey: key,
+		upsert: write{
+			filter: bson.M{"team": team, "date_from": *dateFrom},
+			update: bson.M{"$set": bson.M{"point": point, "date_to": dateTo, "every_weeks": everyWeeks}, "$unset": restore},
+		},
+		span:      &dateSpan{group: team, from: *dateFrom, to: dateTo},
+		unchanged: bson.M{"team": team, "date_from": *dateFrom, "point": point, "date_to": dateTo, "every_weeks": storedEveryWeeks, "deleted_at": nil},
+	}
+}
+
+// Columns: ProjectID, Project, Email, Team, Role, From.
+// Several rows of the same project add one assignment each, Role defaults to owner.
+// Project is a name or an alias of the catalog, an unknown one adds a project with ProjectID or the next free ID.
+func projectDetailOperation(r *rowReader, catalog *collectionmodels.ProjectCatalog) operation {
+	project := r.required("Project")
+	projectID := r.integer("ProjectID", false)
+	if known, ok := catalog.Resolve(project); ok {
+		if projectID != 0 && projectID != known {
+			r.fail("ProjectID", fmt.Sprintf("is %d for this project", known))
+		}
+		projectID = known
+	} else if project != "" {
+		if projectID != 0 && catalog.Name(projectID) != "" {
+			r.fail("ProjectID", "is used by another project")
+		} else if projectID != 0 {
+			catalog.Register(projectID, project)
+		} else {
+			projectID = catalog.Reserve(project)
+		}
+	}
+	email := r.optional("Email")
+	team := r.optional("Team")
+	role := r.optional("Role")
+	from := r.date("From", false)
+	if email != "" && team == "" {
+		r.fail("Team", "is required when Email is set")
+	}
+	if email == "" && team != "" {
+		r.fail("Email", "is required when Team is set")
+	}
+
+	assignments := []collectionmodels.ProjectAssignment{}
+	var then []write
+	if email != "" {
+		a := collectionmodels.ProjectAssignment{Email: email, Team: team, Role: role}
+		if a.Role == "" {
+			a.Role = collectionmodels.ProjectRoleOwner
+		}
+		if from != nil {
+			a.From = *from
+		}
+		assignments = append(assignments, a)
+		// Existing projects get the assignment unless the member already holds it
+		then = append(then, write{
+			filter: bson.M{"id": projectID, "assignments": bson.M{"$not": bson.M{"$elemMatch": bson.M{"email": email, "team": team, "to": nil}}}},
+			update: bson.M{"$push": bson.M{"assignments": a}},
+		})
+	}
+
+	return operation{
+		rowKey:    fmt.Sprintf("%d|%s|%s", projectID, email, team),
+		recordKey: fmt.Sprint(projectID),
+		upsert: write{
+			filter: bson.M{"id": projectID},
+			update: bson.M{
+				"$setOnInsert": bson.M{"project": project, "aliases": []string{}, "assignments": assignments},
+				"$unset":       restore,
+			},
+		},
+		then: then,
+	}
+}
+
+// errRollback aborts the import transaction of a dry run or of rows conflicting with stored records
+var errRollback = errors.New("import rolled back")
+
+// checkTarget returns the row error of a target overlapping a stored target of its team, or changing a closed week.
+// It runs in the import transaction.
+func checkTarget(sc mongo.SessionContext, client *mongo.Client, dbName string, collections collectionmodels.Collections, collection *mongo.Collection, op operation) (*RowError, error) {
+	err := collectionmodels.CheckTeamTargetWritable(sc, collection, op.span.group, op.span.from, op.span.to)
+	if errors.Is(err, collectionmodels.ErrTargetOverlap) {
+		return &RowError{Line: op.line, Message: "overlaps an existing record"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	n, err := collection.CountDocuments(sc, op.unchanged)
+	if err != nil || n > 0 {
+		return nil, err
+	}
+	err = collectionmodels.CheckTeamWeeksOpen(client, dbName, collections.WeekClosure, []string{op.span.group}, op.span.from, op.span.to)
+	if errors.Is(err, collectionmodels.ErrWeekClosed) {
+		return &RowError{Line: op.line, Message: "affects a closed week"}, nil
+	}
+	return nil, err
+}
+
+// Run validates every row and, unless it is a dry run or a row is invalid,
+// applies all of them in a single transaction (MongoDB must run as a replica set, dry runs included).
+func Run(client *mongo.Client, dbName string, collections collectionmodels.Collections, kind Kind, rows []Row, dryRun bool) (*Report, error) {
+	collName, err := kind.Collection(collections)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Kind: kind, DryRun: dryRun, Rows: len(rows), Errors: []RowError{}}
+
+	// Orders are checked against the asset type and project catalogs, new projects are given IDs from the project catalog
+	refs := &references{}
+	if kind == KindWeeklyOrders {
+		refs.assetTypes, err = collectionmodels.GetAllAssetTypes(client, dbName, collections.AssetType, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if kind == KindWeeklyOrders || kind == KindProjectDetails {
+		refs.projects, err = collectionmodels.LoadProjectCatalog(client, dbName, collections.ProjectDetail)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var ops []operation
+	seen := map[string]int{}
+	for _, row := range rows {
+		r := &rowReader{row: row}
+		op, err := buildOperation(kind, r, refs)
+		if err != nil {
+			return nil, err
+		}
+		if len(r.errors) > 0 {
+			report.Errors = append(report.Errors, r.errors...)
+			continue
+		}
+		if line, ok := seen[op.rowKey]; ok {
+			report.Errors = append(report.Errors, RowError{Line: row.Line, Message: fmt.Sprintf("duplicate of line %d", line)})
+			continue
+		}
+		seen[op.rowKey] = row.Line
+		op.line = row.Line
+		if op.span != nil {
+			for _, other := range ops {
+				if other.span != nil && other.span.overlaps(op.span) {
+					report.Errors = append(report.Errors, RowError{Line: row.Line, Message: fmt.Sprintf("overlaps line %d", other.line)})
+					break
+				}
+			}
+		}
+		ops = append(ops, op)
+	}
+	if len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+
+	session, err := client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	// Targets are checked against the stored ones in the transaction that writes them, under the lock the
+	// target writes of the API take. A dry run applies the rows the same way and rolls them back.
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		inserted, updated := 0, 0
+		rowErrors := []RowError{}
+		counted := map[string]bool{}
+		for _, op := range ops {
+			if op.span != nil {
+				rowError, err := checkTarget(sc, client, dbName, collections, collection, op)
+				if err != nil {
+					return nil, err
+				}
+				if rowError != nil {
+					rowErrors = append(rowErrors, *rowError)
+					continue
+				}
+			}
+			res, err := collection.UpdateOne(sc, op.upsert.filter, op.upsert.update, upsertOptions)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", op.line, err)
+			}
+			if !counted[op.recordKey] {
+				counted[op.recordKey] = true
+				if res.UpsertedCount > 0 {
+					inserted++
+				} else {
+					updated++
+				}
+			}
+			for _, w := range op.then {
+				if _, err := collection.UpdateOne(sc, w.filter, w.update); err != nil {
+					return nil, fmt.Errorf("line %d: %w", op.line, err)
+				}
+			}
+		}
+		// The callback can be retried, only keep the outcome of the last attempt
+		report.Inserted, report.Updated, report.Errors = inserted, updated, rowErrors
+		if len(rowErrors) > 0 || dryRun {
+			return nil, errRollback
+		}
+		return nil, nil
+	})
+	if errors.Is(err, errRollback) {
+		if len(report.Errors) > 0 {
+			report.Inserted, report.Updated = 0, 0
+		}
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if kind == KindProjectDetails {
+		// Tasks synced before their project was imported now resolve to it
+		resolved, err := collectionmodels.ResolveTaskProjects(client, dbName, collections.CompletedTask, refs.projects)
+		if err != nil {
+			return nil, err
+		}
+		if resolved.Count > 0 {
+			// The project snapshots of their weeks were built without them
+			lastWeek := calendar.StartOfWeek(resolved.DoneTo)
+			if _, err := collectionmodels.DeletePerformanceSnapshots(client, dbName, collections.PerformanceSnapshot, calendar.StartOfWeek(resolved.DoneFrom), &lastWeek, collectionmodels.KindProject); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return report, nil
+}
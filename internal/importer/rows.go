@@ -0,0 +1,144 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"performance-dashboard-backend/internal/calendar"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported file format, expected .csv or .xlsx")
+
+// Row is one spreadsheet line keyed by its header. Line is the 1-based line number in the file.
+type Row struct {
+	Line   int
+	Values map[string]string
+}
+
+// ReadRows reads a CSV or XLSX file (first sheet) whose first line is the header
+func ReadRows(filename string, r io.Reader) ([]Row, error) {
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		var err error
+		records, err = reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		records, err = f.GetRows(f.GetSheetName(0))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := make([]string, len(records[0]))
+	for i, h := range records[0] {
+		header[i] = strings.TrimSpace(h)
+	}
+
+	var rows []Row
+	for i, record := range records[1:] {
+		values := map[string]string{}
+		empty := true
+		for j, v := range record {
+			if j >= len(header) {
+				break
+			}
+			v = strings.TrimSpace(v)
+			if v != "" {
+				empty = false
+			}
+			values[header[j]] = v
+		}
+		if empty {
+			continue
+		}
+		rows = append(rows, Row{Line: i + 2, Values: values})
+	}
+	return rows, nil
+}
+
+// rowReader collects the validation errors of one row while its fields are read
+type rowReader struct {
+	row    Row
+	errors []RowError
+}
+
+func (r *rowReader) fail(field, message string) {
+	r.errors = append(r.errors, RowError{Line: r.row.Line, Field: field, Message: message})
+}
+
+func (r *rowReader) optional(field string) string {
+	return r.row.Values[field]
+}
+
+func (r *rowReader) required(field string) string {
+	v := r.row.Values[field]
+	if v == "" {
+		r.fail(field, "is required")
+	}
+	return v
+}
+
+func (r *rowReader) integer(field string, required bool) int {
+	v := r.row.Values[field]
+	if v == "" {
+		if required {
+			r.fail(field, "is required")
+		}
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		// Spreadsheets often store whole numbers as floats
+		f, ferr := strconv.ParseFloat(v, 64)
+		if ferr != nil || f != float64(int(f)) {
+			r.fail(field, "must be a whole number")
+			return 0
+		}
+		n = int(f)
+	}
+	if n < 0 {
+		r.fail(field, "must not be negative")
+	}
+	return n
+}
+
+// date accepts RFC3339 or YYYY-MM-DD (midnight in the calendar time zone)
+func (r *rowReader) date(field string, required bool) *time.Time {
+	v := r.row.Values[field]
+	if v == "" {
+		if required {
+			r.fail(field, "is required")
+		}
+		return nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t
+	}
+	if t, err := time.ParseInLocation("2006-01-02", v, calendar.Location()); err == nil {
+		return &t
+	}
+	r.fail(field, "must be a date (YYYY-MM-DD or RFC3339)")
+	return nil
+}
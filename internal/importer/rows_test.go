@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"errors"
+	"performance-dashboard-backend/internal/calendar"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadRows(t *testing.T) {
+	csv := "Email, Name ,Team\n" +
+		"a@example.com, Ann ,Art\n" +
+		",,\n" +
+		"b@example.com,Bob\n" +
+		"c@example.com,Cid,Video,extra\n"
+	rows, err := ReadRows("members.CSV", strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{
+		{Line: 2, Values: map[string]string{"Email": "a@example.com", "Name": "Ann", "Team": "Art"}},
+		{Line: 4, Values: map[string]string{"Email": "b@example.com", "Name": "Bob"}},
+		{Line: 5, Values: map[string]string{"Email": "c@example.com", "Name": "Cid", "Team": "Video"}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("ReadRows = %+v, want %+v", rows, want)
+	}
+
+	if rows, err := ReadRows("empty.csv", strings.NewReader("")); err != nil || rows != nil {
+		t.Errorf("ReadRows of an empty file = %v, %v, want no rows", rows, err)
+	}
+	if _, err := ReadRows("members.txt", strings.NewReader(csv)); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("ReadRows of a .txt error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+}
+
+// fields lists the fields of the errors of a row, in order
+func fields(errs []RowError) []string {
+	res := []string{}
+	for _, e := range errs {
+		res = append(res, e.Field)
+	}
+	return res
+}
+
+func TestRowReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		read   func(r *rowReader) interface{}
+		want   interface{}
+		errors []string
+	}{
+		{"required present", "x", func(r *rowReader) interface{} { return r.required("F") }, "x", []string{}},
+		{"required missing", "", func(r *rowReader) interface{} { return r.required("F") }, "", []string{"F"}},
+		{"integer", "42", func(r *rowReader) interface{} { return r.integer("F", true) }, 42, []string{}},
+		{"integer stored as float", "42.0", func(r *rowReader) interface{} { return r.integer("F", true) }, 42, []string{}},
+		{"integer with a fraction", "4.5", func(r *rowReader) interface{} { return r.integer("F", true) }, 0, []string{"F"}},
+		{"integer not a number", "many", func(r *rowReader) interface{} { return r.integer("F", true) }, 0, []string{"F"}},
+		{"integer negative", "-3", func(r *rowReader) interface{} { return r.integer("F", true) }, -3, []string{"F"}},
+		{"integer optional missing", "", func(r *rowReader) interface{} { return r.integer("F", false) }, 0, []string{}},
+		{"integer required missing", "", func(r *rowReader) interface{} { return r.integer("F", true) }, 0, []string{"F"}},
+		{"date", "2026-03-10", func(r *rowReader) interface{} { return r.date("F", true).Format(time.RFC3339) },
+			time.Date(2026, 3, 10, 0, 0, 0, 0, calendar.Location()).Format(time.RFC3339), []string{}},
+		{"date RFC3339", "2026-03-10T09:00:00Z", func(r *rowReader) interface{} { return r.date("F", true).Format(time.RFC3339) },
+			"2026-03-10T09:00:00Z", []string{}},
+		{"date invalid", "10/03/2026", func(r *rowReader) interface{} { return r.date("F", true) == nil }, true, []string{"F"}},
+		{"date optional missing", "", func(r *rowReader) interface{} { return r.date("F", false) == nil }, true, []string{}},
+		{"date required missing", "", func(r *rowReader) interface{} { return r.date("F", true) == nil }, true, []string{"F"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rowReader{row: Row{Line: 7, Values: map[string]string{"F": tt.value}}}
+			if got := tt.read(r); got != tt.want {
+				t.Errorf("read %q = %v, want %v", tt.value, got, tt.want)
+			}
+			if got := fields(r.errors); !reflect.DeepEqual(got, tt.errors) {
+				t.Errorf("error fields of %q = %v, want %v", tt.value, got, tt.errors)
+			}
+			for _, e := range r.errors {
+				if e.Line != 7 {
+					t.Errorf("error %+v is not on line 7", e)
+				}
+			}
+		})
+	}
+}
+
+func TestOperationValidation(t *testing.T) {
+	refs := &references{
+		assetTypes: []collectionmodels.AssetType{{Key: "banner", Team: "Art"}},
+		projects:   collectionmodels.NewProjectCatalog([]collectionmodels.ProjectDetail{{ProjectID: 1, Project: "Alpha", Aliases: []string{"A"}}}),
+	}
+	tests := []struct {
+		name   string
+		kind   Kind
+		values map[string]string
+		errors []string
+	}{
+		{"member", KindMembers, map[string]string{"MemberID": "m1", "Name": "Ann", "Email": "a@example.com", "Team": "Art", "Role": "lead"}, []string{}},
+		{"member missing fields", KindMembers, map[string]string{"Name": "Ann"}, []string{"MemberID", "Email"}},
+		{"member role without team", KindMembers, map[string]string{"MemberID": "m1", "Name": "Ann", "Email": "a@example.com", "Role": "lead"}, []string{"Team"}},
+		{"member work percent above 100", KindMembers, map[string]string{"MemberID": "m1", "Name": "Ann", "Email": "a@example.com", "WorkPercent": "120"}, []string{"WorkPercent"}},
+		{"order", KindWeeklyOrders, map[string]string{"StartWeek": "2026-03-10", "Project": "A", "AssetType": "banner", "Quantity": "3"}, []string{}},
+		{"order unknown project", KindWeeklyOrders, map[string]string{"StartWeek": "2026-03-10", "Project": "Beta", "AssetType": "banner", "Quantity": "3"}, []string{"Project"}},
+		{"order unknown asset type", KindWeeklyOrders, map[string]string{"StartWeek": "2026-03-10", "Project": "Alpha", "AssetType": "video", "Quantity": "3"}, []string{"AssetType"}},
+		{"order zero quantity", KindWeeklyOrders, map[string]string{"StartWeek": "2026-03-10", "Project": "Alpha", "AssetType": "banner", "Quantity": "0"}, []string{"Quantity"}},
+		{"order invalid priority", KindWeeklyOrders, map[string]string{"StartWeek": "2026-03-10", "Project": "Alpha", "AssetType": "banner", "Quantity": "1", "Priority": "asap"}, []string{"AssetType"}},
+		{"target", KindWeeklyTargets, map[string]string{"Team": "Art", "Point": "40", "DateFrom": "2026-03-10", "DateTo": "2026-06-01"}, []string{}},
+		{"target missing fields", KindWeeklyTargets, map[string]string{"DateFrom": "2026-03-10"}, []string{"Team", "Point"}},
+		{"target ending before it starts", KindWeeklyTargets, map[string]string{"Team": "Art", "Point": "40", "DateFrom": "2026-03-10", "DateTo": "2026-03-01"}, []string{"DateTo"}},
+		{"project", KindProjectDetails, map[string]string{"Project": "Gamma", "Email": "a@example.com", "Team": "Art"}, []string{}},
+		{"project with the ID of another", KindProjectDetails, map[string]string{"Project": "Gamma", "ProjectID": "1"}, []string{"ProjectID"}},
+		{"project with another ID", KindProjectDetails, map[string]string{"Project": "A", "ProjectID": "2"}, []string{"ProjectID"}},
+		{"project email without team", KindProjectDetails, map[string]string{"Project": "Alpha", "Email": "a@example.com"}, []string{"Team"}},
+		{"project team without email", KindProjectDetails, map[string]string{"Project": "Alpha", "Team": "Art"}, []string{"Email"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rowReader{row: Row{Line: 2, Values: tt.values}}
+			if _, err := buildOperation(tt.kind, r, refs); err != nil {
+				t.Fatal(err)
+			}
+			if got := fields(r.errors); !reflect.DeepEqual(got, tt.errors) {
+				t.Errorf("error fields = %v, want %v: %+v", got, tt.errors, r.errors)
+			}
+		})
+	}
+	if _, err := buildOperation("unknown", &rowReader{}, refs); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("buildOperation of an unknown kind error = %v, want %v", err, ErrUnknownKind)
+	}
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+var webhookURL string
+
+// Configure sets the Slack incoming webhook notifications are posted to, none when empty
+func Configure(url string) {
+	webhookURL = url
+}
+
+// Send posts text to the Slack incoming webhook set by Configure, or logs it when no webhook is set.
+// Delivery happens in the background, failures are only logged.
+func Send(text string) {
+	url := webhookURL
+	if url == "" {
+		log.Println("Notification:", text)
+		return
+	}
+	go func() {
+		if err := post(url, text); err != nil {
+			log.Println("Error sending notification:", err)
+		}
+	}()
+}
+
+func post(url, text string) error {
+	data, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package report
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	db "performance-dashboard-backend/internal/database"
+	"strconv"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+const dateLayout = "2006-01-02"
+
+// The core PDF fonts only cover cp1252, member and team names are written with an embedded Unicode font
+const pdfFont = "DejaVu"
+
+var (
+	//go:embed fonts/DejaVuSansCondensed.ttf
+	pdfFontRegular []byte
+	//go:embed fonts/DejaVuSansCondensed-Bold.ttf
+	pdfFontBold []byte
+)
+
+var performanceHeader = []string{"Identifier", "Week Start", "Week End", "Base Point", "Creative Task Point", "Creative Process Point", "Adjustment Point", "Total Point"}
+
+// PerformanceRow is one identifier (member or team) over one period
+type PerformanceRow struct {
+	Identifier      string
+	StartDate       time.Time
+	EndDate         time.Time
+	Base            float64
+	CreativeTask    float64
+	CreativeProcess float64
+	Adjustment      float64
+	Total           float64
+}
+
+// TeamSummary is the content of one team section of the PDF report
+type TeamSummary struct {
+	Team      string
+	StartDate time.Time
+	EndDate   time.Time
+	Weeks     []PerformanceRow
+	Members   []PerformanceRow
+}
+
+func RowsFromPerformance(points []db.PerformancePointTotalWithTime) []PerformanceRow {
+	rows := make([]PerformanceRow, 0, len(points))
+	for _, p := range points {
+		rows = append(rows, PerformanceRow{
+			Identifier:      p.TotalPerformancePoint.Identifier,
+			StartDate:       p.StartDate,
+			EndDate:         p.EndDate,
+			Base:            p.TotalPerformancePoint.TotalBasePoint,
+			CreativeTask:    p.TotalPerformancePoint.TotalCreativeTaskPoint,
+			CreativeProcess: p.TotalPerformancePoint.TotalCreativeProcessPoint,
+			Adjustment:      p.TotalPerformancePoint.TotalAdjustmentPoint,
+			Total:           p.TotalPerformancePoint.TotalPerformancePoint,
+		})
+	}
+	return rows
+}
+
+func formatPoint(p float64) string {
+	return strconv.FormatFloat(p, 'f', 2, 64)
+}
+
+func (r PerformanceRow) record() []string {
+	return []string{
+		r.Identifier,
+		r.StartDate.Format(dateLayout),
+		r.EndDate.Format(dateLayout),
+		formatPoint(r.Base),
+		formatPoint(r.CreativeTask),
+		formatPoint(r.CreativeProcess),
+		formatPoint(r.Adjustment),
+		formatPoint(r.Total),
+	}
+}
+
+func WriteCSV(w io.Writer, rows []PerformanceRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(performanceHeader); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := writer.Write(r.record()); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func WriteXLSX(w io.Writer, rows []PerformanceRow) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := "Performance"
+	f.SetSheetName(f.GetSheetName(0), sheet)
+
+	for i, h := range performanceHeader {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheet, cell, h)
+	}
+	for i, r := range rows {
+		values := []interface{}{r.Identifier, r.StartDate.Format(dateLayout), r.EndDate.Format(dateLayout), r.Base, r.CreativeTask, r.CreativeProcess, r.Adjustment, r.Total}
+		for j, v := range values {
+			cell, _ := excelize.CoordinatesToCellName(j+1, i+2)
+			f.SetCellValue(sheet, cell, v)
+		}
+	}
+	f.SetColWidth(sheet, "A", "A", 30)
+	f.SetColWidth(sheet, "B", "H", 18)
+
+	_, err := f.WriteTo(w)
+	return err
+}
+
+// WriteTeamPDF renders one printable page per team: weekly totals then the period total of each member
+func WriteTeamPDF(w io.Writer, summaries []TeamSummary) error {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(pdfFont, "", pdfFontRegular)
+	pdf.AddUTF8FontFromBytes(pdfFont, "B", pdfFontBold)
+	widths := []float64{60, 28, 28, 30, 32, 32, 28, 28}
+
+	table := func(title string, rows []PerformanceRow) {
+		pdf.SetFont(pdfFont, "B", 12)
+		pdf.CellFormat(0, 8, title, "", 1, "L", false, 0, "")
+		pdf.SetFont(pdfFont, "B", 9)
+		for i, h := range performanceHeader {
+			pdf.CellFormat(widths[i], 7, h, "1", 0, "C", false, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont(pdfFont, "", 9)
+		var total PerformanceRow
+		for _, r := range rows {
+			for i, v := range r.record() {
+				align := "R"
+				if i == 0 {
+					align = "L"
+				}
+				pdf.CellFormat(widths[i], 6, v, "1", 0, align, false, 0, "")
+			}
+			pdf.Ln(-1)
+			total.Base += r.Base
+			total.CreativeTask += r.CreativeTask
+			total.CreativeProcess += r.CreativeProcess
+			total.Adjustment += r.Adjustment
+			total.Total += r.Total
+		}
+		pdf.SetFont(pdfFont, "B", 9)
+		pdf.CellFormat(widths[0]+widths[1]+widths[2], 6, "Total", "1", 0, "L", false, 0, "")
+		for i, v := range []float64{total.Base, total.CreativeTask, total.CreativeProcess, total.Adjustment, total.Total} {
+			pdf.CellFormat(widths[i+3], 6, formatPoint(v), "1", 0, "R", false, 0, "")
+		}
+		pdf.Ln(10)
+	}
+
+	for _, s := range summaries {
+		pdf.AddPage()
+		pdf.SetFont(pdfFont, "B", 16)
+		pdf.CellFormat(0, 10, s.Team, "", 1, "L", false, 0, "")
+		pdf.SetFont(pdfFont, "", 10)
+		pdf.CellFormat(0, 6, fmt.Sprintf("Performance from %s to %s", s.StartDate.Format(dateLayout), s.EndDate.Format(dateLayout)), "", 1, "L", false, 0, "")
+		pdf.Ln(4)
+		table("Weekly totals", s.Weeks)
+		table("Members", s.Members)
+	}
+	if len(summaries) == 0 {
+		pdf.AddPage()
+		pdf.SetFont(pdfFont, "", 12)
+		pdf.CellFormat(0, 10, "No data for the selected period", "", 1, "L", false, 0, "")
+	}
+	return pdf.Output(w)
+}